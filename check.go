@@ -2,6 +2,7 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"strings"
@@ -11,40 +12,51 @@ import (
 
 // CheckDomain queries the EPP server for the availability status of one or more domains.
 func (c *Conn) CheckDomain(domains ...string) (*DomainCheckResponse, error) {
-	return c.CheckDomainExtensions(domains, nil)
+	return c.CheckDomainExtensionsContext(context.Background(), domains, nil)
+}
+
+// CheckDomainContext is like CheckDomain, but aborts if ctx is done before the server responds.
+func (c *Conn) CheckDomainContext(ctx context.Context, domains ...string) (*DomainCheckResponse, error) {
+	return c.CheckDomainExtensionsContext(ctx, domains, nil)
 }
 
 // CheckDomainExtensions allows specifying extension data for the following:
 //   - "neulevel:unspec": a string of the Key=Value data for the unspec tag
 //   - "launch:phase": a string of the launch phase
 func (c *Conn) CheckDomainExtensions(domains []string, extData map[string]string) (*DomainCheckResponse, error) {
-	x, err := encodeDomainCheck(&c.Greeting, domains, extData)
+	return c.CheckDomainExtensionsContext(context.Background(), domains, extData)
+}
+
+// CheckDomainExtensionsContext is like CheckDomainExtensions, but aborts if ctx is done before the server responds.
+func (c *Conn) CheckDomainExtensionsContext(ctx context.Context, domains []string, extData map[string]string) (*DomainCheckResponse, error) {
+	g := c.LastGreeting()
+	x, err := encodeDomainCheck(&g, domains, extData)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.writeRequest(x)
+	tx, err := c.writeRequest(ctx, x)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.readResponse()
+	res, err := c.readResponse(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 
 	// The ARI price extension won't return both availability and price data
 	// in the same response, so we have to make a separate request for price
-	if c.Greeting.SupportsExtension(ExtPrice) {
+	if g.SupportsExtension(ExtPrice) {
 		x, err = encodePriceCheck(domains)
 		if err != nil {
 			return nil, err
 		}
-		err = c.writeRequest(x)
+		tx, err = c.writeRequest(ctx, x)
 		if err != nil {
 			return nil, err
 		}
-		res2, err := c.readResponse()
+		res2, err := c.readResponse(ctx, tx)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,210 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Client is an EPP client that survives session loss. It shares a single
+// logged-in Conn among callers. If the server closes the connection or
+// returns a fatal result (code 2500 or higher, e.g. 2502 session limit
+// exceeded), Client re-dials, reads the new greeting and logs in again
+// with the same credentials on the next command.
+//
+// Idempotent commands (check, info and poll request) are retried once on a
+// new session. Other commands are never retried, since the server may have
+// processed them before the session was lost; use Do to issue them.
+// Client is safe for concurrent use.
+type Client struct {
+	// Dial opens a new EPP connection, with the server greeting read.
+	Dial func(ctx context.Context) (*Conn, error)
+
+	// User and Password are the credentials used to log in each session.
+	User     string
+	Password string
+
+	// LoginOptions, if set, are used to log in each session.
+	LoginOptions *LoginOptions
+
+	// Open, if set, opens a new logged-in session, and is used instead of
+	// Dial, User, Password and LoginOptions. For example, set Open to the
+	// Open method of a PasswordRotator to log in with rotated credentials.
+	Open func(ctx context.Context) (*Conn, error)
+
+	// Limiter, if set, limits the rate of commands sent in each session.
+	Limiter RateLimiter
+
+	// m protects conn.
+	m    sync.Mutex
+	conn *Conn
+}
+
+// Do calls f with the current session, logging in a new session if
+// necessary. It does not retry f. If f returns an error indicating the
+// session was lost, the session is discarded, and the next command will
+// use a new session.
+func (cl *Client) Do(ctx context.Context, f func(*Conn) error) error {
+	return cl.do(ctx, false, f)
+}
+
+// CheckDomain is like Conn.CheckDomainContext, retrying once on session loss.
+func (cl *Client) CheckDomain(ctx context.Context, domains ...string) (dcr *DomainCheckResponse, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		dcr, err = c.CheckDomainContext(ctx, domains...)
+		return err
+	})
+	return dcr, err
+}
+
+// DomainInfo is like Conn.DomainInfoWithOptionsContext, retrying once on session loss.
+func (cl *Client) DomainInfo(ctx context.Context, domain string, opts *DomainInfoOptions) (dir *DomainInfoResponse, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		dir, err = c.DomainInfoWithOptionsContext(ctx, domain, opts)
+		return err
+	})
+	return dir, err
+}
+
+// HostCheck is like Conn.HostCheckContext, retrying once on session loss.
+func (cl *Client) HostCheck(ctx context.Context, hosts ...string) (hcr *HostCheckResponse, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		hcr, err = c.HostCheckContext(ctx, hosts...)
+		return err
+	})
+	return hcr, err
+}
+
+// HostInfo is like Conn.HostInfoContext, retrying once on session loss.
+func (cl *Client) HostInfo(ctx context.Context, host string) (hir *HostInfoResponse, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		hir, err = c.HostInfoContext(ctx, host)
+		return err
+	})
+	return hir, err
+}
+
+// ContactCheck is like Conn.ContactCheckContext, retrying once on session loss.
+func (cl *Client) ContactCheck(ctx context.Context, ids ...string) (ccr *ContactCheckResponse, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		ccr, err = c.ContactCheckContext(ctx, ids...)
+		return err
+	})
+	return ccr, err
+}
+
+// ContactInfo is like Conn.ContactInfoContext, retrying once on session loss.
+func (cl *Client) ContactInfo(ctx context.Context, id, authInfo string) (cir *ContactInfoResponse, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		cir, err = c.ContactInfoContext(ctx, id, authInfo)
+		return err
+	})
+	return cir, err
+}
+
+// PollRequest is like Conn.PollRequestContext, retrying once on session loss.
+// Retrying is safe since a message stays queued until acknowledged.
+func (cl *Client) PollRequest(ctx context.Context) (msg *PollMessage, err error) {
+	err = cl.do(ctx, true, func(c *Conn) error {
+		msg, err = c.PollRequestContext(ctx)
+		return err
+	})
+	return msg, err
+}
+
+// Close logs out and closes the current session, if any.
+func (cl *Client) Close() error {
+	cl.m.Lock()
+	defer cl.m.Unlock()
+	if cl.conn == nil {
+		return nil
+	}
+	err := cl.conn.Close()
+	cl.conn = nil
+	return err
+}
+
+// do calls f with the current session. If the session is lost,
+// it is discarded and, if retry is true, f is called once more
+// with a new session.
+func (cl *Client) do(ctx context.Context, retry bool, f func(*Conn) error) error {
+	c, err := cl.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = f(c)
+	if !sessionLost(c, err) {
+		return err
+	}
+	cl.discard(c)
+	if !retry || ctx.Err() != nil {
+		return err
+	}
+	c, err = cl.session(ctx)
+	if err != nil {
+		return err
+	}
+	err = f(c)
+	if sessionLost(c, err) {
+		cl.discard(c)
+	}
+	return err
+}
+
+// session returns the current session, opening a new one if necessary.
+func (cl *Client) session(ctx context.Context) (*Conn, error) {
+	cl.m.Lock()
+	defer cl.m.Unlock()
+	if cl.conn != nil && !cl.conn.closed() {
+		return cl.conn, nil
+	}
+	c, err := openSession(ctx, cl.Open, cl.Dial, cl.User, cl.Password, cl.LoginOptions, cl.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	cl.conn = c
+	return c, nil
+}
+
+// discard closes session c, and forgets it if it is the current session.
+func (cl *Client) discard(c *Conn) {
+	cl.m.Lock()
+	if cl.conn == c {
+		cl.conn = nil
+	}
+	cl.m.Unlock()
+	c.Conn.Close()
+}
+
+// openSession opens a new logged-in session with open, if set, and attaches
+// limiter. Otherwise it dials a new connection, attaches limiter and logs in.
+func openSession(ctx context.Context, open, dial func(context.Context) (*Conn, error), user, password string, opts *LoginOptions, limiter RateLimiter) (*Conn, error) {
+	if open != nil {
+		c, err := open(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if limiter != nil {
+			c.Limiter = limiter
+		}
+		return c, nil
+	}
+	c, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.Limiter = limiter
+	_, err = c.LoginWithOptionsContext(ctx, user, password, "", opts)
+	if err != nil {
+		c.Conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// sessionLost reports whether session c is unusable after a command
+// returned err, because the connection was closed or err is a fatal Result.
+func sessionLost(c *Conn, err error) bool {
+	var r *Result
+	return errors.As(err, &r) && r.IsFatal() || c.closed()
+}
@@ -0,0 +1,87 @@
+package epp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestClientRetryAfterFatalResult(t *testing.T) {
+	s := testPoolServer{
+		fail: func(n int32, x string) int {
+			if n == 1 {
+				return ResultSessionLimitExceeded
+			}
+			return ResultSuccess
+		},
+	}
+	cl := &Client{Dial: s.dial, User: "user", Password: "pass"}
+	defer cl.Close()
+
+	_, err := cl.CheckDomain(context.Background(), "example.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, s.dials.Load(), int32(2))
+	st.Expect(t, s.logins.Load(), int32(2))
+}
+
+func TestClientRetryAfterConnectionLoss(t *testing.T) {
+	s := testPoolServer{
+		fail: func(n int32, x string) int {
+			if n == 1 {
+				return 0
+			}
+			return ResultSuccess
+		},
+	}
+	cl := &Client{Dial: s.dial, User: "user", Password: "pass"}
+	defer cl.Close()
+
+	_, err := cl.DomainInfo(context.Background(), "example.com", nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, s.dials.Load(), int32(2))
+}
+
+func TestClientRetriesOnce(t *testing.T) {
+	s := testPoolServer{
+		fail: func(n int32, x string) int {
+			return ResultCommandFailedServerClosingConnection
+		},
+	}
+	cl := &Client{Dial: s.dial, User: "user", Password: "pass"}
+	defer cl.Close()
+
+	_, err := cl.PollRequest(context.Background())
+	st.Expect(t, err.(*Result).Code, ResultCommandFailedServerClosingConnection)
+	st.Expect(t, s.dials.Load(), int32(2))
+}
+
+func TestClientDoDoesNotRetry(t *testing.T) {
+	s := testPoolServer{
+		fail: func(n int32, x string) int {
+			if n == 1 {
+				return 0
+			}
+			return ResultSuccess
+		},
+	}
+	cl := &Client{Dial: s.dial, User: "user", Password: "pass"}
+	defer cl.Close()
+
+	calls := 0
+	err := cl.Do(context.Background(), func(c *Conn) error {
+		calls++
+		_, err := c.DomainDeleteContext(context.Background(), "example.com")
+		return err
+	})
+	st.Reject(t, err, nil)
+	st.Expect(t, calls, 1)
+
+	// The next command uses a new session.
+	err = cl.Do(context.Background(), func(c *Conn) error {
+		_, err := c.DomainDeleteContext(context.Background(), "example.com")
+		return err
+	})
+	st.Expect(t, err, nil)
+	st.Expect(t, s.dials.Load(), int32(2))
+}
@@ -1,14 +1,28 @@
 package epp
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
 	"net"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	errUnknownTransaction = errors.New("epp: response to unknown client transaction ID")
+	errNotGreeting        = errors.New("epp: expected greeting")
+)
+
 // IgnoreEOF returns err unless err == io.EOF,
 // in which case it returns nil.
 func IgnoreEOF(err error) error {
@@ -19,7 +33,13 @@ func IgnoreEOF(err error) error {
 }
 
 // Conn represents a single connection to an EPP server.
-// Reads and writes are serialized, so it is safe for concurrent use.
+// It is safe for concurrent use. Writes are serialized, and each command
+// is tagged with a unique client transaction ID (<clTRID>). A reader
+// goroutine matches each response to its request by the echoed ID,
+// so multiple requests may be in flight (pipelined) at once.
+//
+// Methods with a Context suffix abort when their context is canceled or its
+// deadline expires. A response that arrives for an aborted request is discarded.
 type Conn struct {
 	// Conn is the underlying net.Conn (usually a TLS connection).
 	net.Conn
@@ -29,24 +49,73 @@ type Conn struct {
 	// a connection is already opened will have no effect.
 	Timeout time.Duration
 
+	// Limiter, if set, limits the rate of commands sent on c.
+	// It must be set before sending any commands.
+	Limiter RateLimiter
+
 	// m protects Greeting.
 	m sync.Mutex
 
 	// Greeting holds the last received greeting message from the server,
 	// indicating server name, status, data policy and capabilities.
 	//
-	// Deprecated: This field is written to upon opening a new EPP connection and should not be modified.
+	// Deprecated: This field is written to upon opening a new EPP connection
+	// and by Hello (including Keepalive), so reading it directly may race.
+	// Use LastGreeting instead.
 	Greeting
 
-	// mRead synchronizes connection reads.
-	mRead sync.Mutex
-
 	// mWrite synchronizes connection writes.
 	mWrite sync.Mutex
 
+	// mPending protects pending, written, abandoned and readErr.
+	mPending sync.Mutex
+
+	// pending holds outstanding requests, oldest first.
+	pending []*transaction
+
+	// written counts the requests written to c.
+	written uint64
+
+	// abandoned maps the IDs of commands whose callers stopped waiting
+	// for a response to their sequence numbers, so their responses can
+	// be dropped. Since the server responds in order, an entry is evicted
+	// when a command written after it is answered.
+	abandoned map[string]uint64
+
+	// readErr is the error that stopped the reader goroutine.
+	readErr error
+
+	// readDone is closed when the reader goroutine exits.
+	readDone chan struct{}
+
+	// trPrefix and trSeq generate client transaction IDs.
+	trPrefix string
+	trSeq    atomic.Uint64
+
+	// mNotify protects notify.
+	mNotify sync.Mutex
+
+	// notify holds channels registered with NotifyMessageQueue.
+	notify []chan<- MessageQueue
+
+	// lastWrite is when a request was last written, in Unix nanoseconds.
+	lastWrite atomic.Int64
+
+	// msgQCount is the last known message queue count.
+	// It is only accessed by the reader goroutine.
+	msgQCount int
+
 	done chan struct{}
 }
 
+// transaction represents an EPP request awaiting its response.
+type transaction struct {
+	id  string // client transaction ID, or empty if not a command
+	seq uint64 // sequence number in the order written to the connection
+	res chan *Response
+	err chan error
+}
+
 // NewConn initializes an epp.Conn from a net.Conn and performs the EPP
 // handshake. It reads and stores the initial EPP <greeting> message.
 // https://tools.ietf.org/html/rfc5730#section-2.4
@@ -54,69 +123,434 @@ func NewConn(conn net.Conn) (*Conn, error) {
 	return NewTimeoutConn(conn, 0)
 }
 
-// NewTimeoutConn initializes an epp.Conn like NewConn, limiting the duration of network
-// operations on conn using Set(Read|Write)Deadline.
+// NewTimeoutConn initializes an epp.Conn like NewConn, limiting the duration of
+// writes on conn using SetWriteDeadline, and the time spent waiting for each response.
 func NewTimeoutConn(conn net.Conn, timeout time.Duration) (*Conn, error) {
 	c := &Conn{
-		Conn:    conn,
-		Timeout: timeout,
-		done:    make(chan struct{}),
+		Conn:     conn,
+		Timeout:  timeout,
+		readDone: make(chan struct{}),
+		trPrefix: newTransactionPrefix(),
+		done:     make(chan struct{}),
 	}
 	g, err := c.readGreeting()
-	if err == nil {
-		c.m.Lock()
-		c.Greeting = g
-		c.m.Unlock()
+	if err != nil {
+		c.readErr = err
+		close(c.readDone)
+		return c, err
 	}
-	return c, err
+	c.m.Lock()
+	c.Greeting = g
+	c.m.Unlock()
+	c.lastWrite.Store(time.Now().UnixNano())
+	go c.readLoop()
+	return c, nil
 }
 
 // Close sends an EPP <logout> command and closes the connection c.
 func (c *Conn) Close() error {
+	return c.close(context.Background())
+}
+
+// close is like Close, but stops waiting for the
+// <logout> response when ctx is done.
+func (c *Conn) close(ctx context.Context) error {
 	select {
 	case <-c.done:
 		return net.ErrClosed
 	default:
 	}
-	c.Logout()
+	c.LogoutContext(ctx)
 	close(c.done)
-	return c.Conn.Close()
+	err := c.Conn.Close()
+	<-c.readDone
+	return err
+}
+
+// LastGreeting returns a copy of the last greeting received on c.
+// It is safe to call while Hello or Keepalive may update the greeting.
+// The slices in the returned Greeting must not be modified.
+func (c *Conn) LastGreeting() Greeting {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.Greeting
+}
+
+// closed reports whether c is no longer usable, because the
+// connection was closed or the reader goroutine exited.
+func (c *Conn) closed() bool {
+	select {
+	case <-c.readDone:
+		return true
+	default:
+		return false
+	}
 }
 
 // writeRequest writes a single EPP request (x) for writing on c.
+// If x is an EPP <command>, a unique <clTRID> is added to it.
+// The returned transaction is passed to readResponse to receive the response.
 // writeRequest can be called from multiple goroutines.
-func (c *Conn) writeRequest(x []byte) error {
+func (c *Conn) writeRequest(ctx context.Context, x []byte) (*transaction, error) {
+	tx := &transaction{
+		res: make(chan *Response, 1),
+		err: make(chan error, 1),
+	}
+	if bytes.HasSuffix(x, []byte(xmlCommandSuffix)) {
+		if c.Limiter != nil {
+			err := c.Limiter.Wait(ctx, commandClass(x))
+			if err != nil {
+				return nil, err
+			}
+		}
+		tx.id = c.nextTransactionID()
+		x = withClientTransactionID(x, tx.id)
+	}
+
 	c.mWrite.Lock()
 	defer c.mWrite.Unlock()
-	if c.Timeout > 0 {
-		c.Conn.SetWriteDeadline(time.Now().Add(c.Timeout))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mPending.Lock()
+	err := c.readErr
+	if err == nil {
+		c.written++
+		tx.seq = c.written
+		c.pending = append(c.pending, tx)
+	}
+	c.mPending.Unlock()
+	if err != nil {
+		return nil, err
 	}
-	return writeDataUnit(c.Conn, x)
+	stop := c.setDeadline(ctx, c.Conn.SetWriteDeadline)
+	defer stop()
+	err = writeDataUnit(c.Conn, x)
+	if err != nil {
+		c.forget(tx)
+		// A partially written data unit corrupts the stream.
+		c.Conn.Close()
+		return nil, contextError(ctx, err)
+	}
+	c.lastWrite.Store(time.Now().UnixNano())
+	return tx, nil
 }
 
-// readResponse dequeues and returns a EPP response from c.
+// readResponse waits for and returns the EPP response to tx.
 // It returns an error if the EPP response contains an error Result.
 // readResponse can be called from multiple goroutines.
-func (c *Conn) readResponse() (*Response, error) {
-	c.mRead.Lock()
-	defer c.mRead.Unlock()
+func (c *Conn) readResponse(ctx context.Context, tx *transaction) (*Response, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, c.Timeout, os.ErrDeadlineExceeded)
+		defer cancel()
+	}
+	select {
+	case res := <-tx.res:
+		return checkResponse(res)
+	case err := <-tx.err:
+		return nil, err
+	case <-ctx.Done():
+		c.forget(tx)
+		return nil, context.Cause(ctx)
+	case <-c.readDone:
+	}
+	// The reader may have delivered a response before exiting.
+	select {
+	case res := <-tx.res:
+		return checkResponse(res)
+	case err := <-tx.err:
+		return nil, err
+	default:
+	}
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	return nil, c.readErr
+}
+
+// checkResponse returns res and an error if res contains an error Result.
+// The error is a *Result, or a *ResultError if res has more than one result.
+func checkResponse(res *Response) (*Response, error) {
+	if !res.Result.IsError() {
+		return res, nil
+	}
+	if len(res.Results) > 1 {
+		return res, &ResultError{Results: res.Results}
+	}
+	return res, &res.Result
+}
+
+// readGreeting reads the initial <greeting> from the server.
+// It must be called before the reader goroutine is started.
+func (c *Conn) readGreeting() (Greeting, error) {
 	if c.Timeout > 0 {
 		c.Conn.SetReadDeadline(time.Now().Add(c.Timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
 	}
-	n, err := readDataUnitHeader(c.Conn)
+	res, err := readDataUnit(c.Conn)
 	if err != nil {
-		return nil, err
+		return Greeting{}, err
+	}
+	if !res.isGreeting {
+		return Greeting{}, errNotGreeting
+	}
+	return res.Greeting, nil
+}
+
+// readLoop reads responses from c and dispatches them to pending requests
+// until the connection is closed or a network error occurs.
+func (c *Conn) readLoop() {
+	defer close(c.readDone)
+	for {
+		res, err := readDataUnit(c.Conn)
+		if res == nil {
+			c.fail(err)
+			return
+		}
+		if err == nil {
+			c.observeMessageQueue(res)
+		}
+		err = c.dispatch(res, err)
+		if err != nil {
+			// Some request would otherwise wait forever for its response.
+			c.fail(err)
+			c.Conn.Close()
+			return
+		}
+	}
+}
+
+// fail stops c from accepting requests, failing all pending requests with err.
+func (c *Conn) fail(err error) {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	c.readErr = err
+	c.pending = nil
+}
+
+// NotifyMessageQueue causes c to send the <msgQ> state of a response to ch
+// whenever it reports more queued messages than previously known, so the
+// caller can start polling. Any EPP response may carry a <msgQ> element.
+// Sends on ch do not block: if ch is not ready, the notification is dropped,
+// so ch should be buffered.
+func (c *Conn) NotifyMessageQueue(ch chan<- MessageQueue) {
+	c.mNotify.Lock()
+	defer c.mNotify.Unlock()
+	c.notify = append(c.notify, ch)
+}
+
+// observeMessageQueue tracks the message queue count reported in res,
+// notifying registered channels if it rose.
+func (c *Conn) observeMessageQueue(res *Response) {
+	mq := res.MessageQueue
+	switch {
+	case res.Result.Code == ResultSuccessNoMessages:
+		c.msgQCount = 0
+		return
+	case mq.ID == "" && mq.Count == 0:
+		// No <msgQ> element.
+		return
+	}
+	rose := mq.Count > c.msgQCount
+	c.msgQCount = mq.Count
+	if !rose {
+		return
+	}
+	c.mNotify.Lock()
+	defer c.mNotify.Unlock()
+	for _, ch := range c.notify {
+		select {
+		case ch <- mq:
+		default:
+		}
 	}
-	r := io.LimitedReader{R: c.Conn, N: int64(n)}
-	res := &Response{}
-	err = IgnoreEOF(scanResponse.Scan(xml.NewDecoder(&r), res))
+}
+
+// dispatch delivers res (or err, if res could not be parsed) to the
+// pending request with a matching client transaction ID. A <greeting>
+// is delivered to the oldest pending request without an ID (a <hello>).
+// Other responses without a <clTRID> are delivered to the oldest pending
+// command. Responses to abandoned requests are dropped.
+// It returns an error if the response cannot be matched to its request:
+// if it could not be parsed and has no <clTRID>, or its <clTRID> is unknown.
+func (c *Conn) dispatch(res *Response, err error) error {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	id := res.ClientTransactionID
+	if id == "" && err != nil {
+		// The scan stopped before <trID>.
+		id = clientTransactionID(res.raw)
+		if id == "" {
+			return fmt.Errorf("epp: unmatched response: %w", err)
+		}
+	}
+	i := -1
+	if id == "" {
+		for j, tx := range c.pending {
+			if (tx.id == "") == res.isGreeting {
+				i = j
+				break
+			}
+		}
+	} else {
+		for j, tx := range c.pending {
+			if tx.id == id {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			seq, ok := c.abandoned[id]
+			if !ok {
+				return errUnknownTransaction
+			}
+			c.evictAbandoned(seq)
+			return nil
+		}
+	}
+	if i < 0 {
+		return nil
+	}
+	tx := c.pending[i]
+	if tx.id != "" {
+		c.evictAbandoned(tx.seq)
+	}
+	c.pending = append(c.pending[:i], c.pending[i+1:]...)
 	if err != nil {
-		return res, err
+		tx.err <- err
+	} else {
+		tx.res <- res
 	}
-	if res.Result.IsError() {
-		return res, &res.Result
+	return nil
+}
+
+// clientTransactionID returns the <clTRID> in the <trID> of
+// EPP response p, or an empty string if there is none.
+func clientTransactionID(p []byte) string {
+	d := xml.NewDecoder(bytes.NewReader(p))
+	var path []string
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			return ""
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			n := len(path)
+			if n >= 2 && path[n-2] == "trID" && path[n-1] == "clTRID" {
+				return string(bytes.TrimSpace(t))
+			}
+		}
 	}
-	return res, err
+}
+
+// forget removes tx from the pending requests on c.
+// A response to tx that arrives later is dropped.
+func (c *Conn) forget(tx *transaction) {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for i, t := range c.pending {
+		if t == tx {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			if tx.id != "" {
+				if c.abandoned == nil {
+					c.abandoned = make(map[string]uint64)
+				}
+				c.abandoned[tx.id] = tx.seq
+			}
+			return
+		}
+	}
+}
+
+// evictAbandoned removes abandoned commands written no later than
+// the command with sequence number seq, whose response was received.
+// Their responses will not arrive. c.mPending must be held.
+func (c *Conn) evictAbandoned(seq uint64) {
+	for id, s := range c.abandoned {
+		if s <= seq {
+			delete(c.abandoned, id)
+		}
+	}
+}
+
+// nextTransactionID returns a unique client transaction ID for a command on c.
+func (c *Conn) nextTransactionID() string {
+	return c.trPrefix + "-" + strconv.FormatUint(c.trSeq.Add(1), 10)
+}
+
+// newTransactionPrefix returns a random prefix for client transaction IDs,
+// so IDs are unique across connections.
+func newTransactionPrefix() string {
+	var b [6]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// withClientTransactionID returns EPP command x with a <clTRID> element
+// containing id inserted before the closing </command> tag.
+// If x is not an EPP command, it is returned unmodified.
+func withClientTransactionID(x []byte, id string) []byte {
+	if !bytes.HasSuffix(x, []byte(xmlCommandSuffix)) {
+		return x
+	}
+	n := len(x) - len(xmlCommandSuffix)
+	y := make([]byte, 0, len(x)+len(id)+len(`<clTRID></clTRID>`))
+	y = append(y, x[:n]...)
+	y = append(y, `<clTRID>`...)
+	y = append(y, id...)
+	y = append(y, `</clTRID>`...)
+	y = append(y, xmlCommandSuffix...)
+	return y
+}
+
+// setDeadline sets a deadline on c using set, such as
+// c.Conn.SetWriteDeadline. The deadline is
+// the earlier of c.Timeout (if set) and the deadline of ctx (if any).
+// If ctx is canceled before the returned stop func is called, the
+// deadline is moved into the past to abort any pending network operation.
+// The stop func clears the deadline, after waiting for any cancellation
+// in progress, so it cannot affect a later operation.
+func (c *Conn) setDeadline(ctx context.Context, set func(time.Time) error) (stop func()) {
+	var t time.Time
+	if c.Timeout > 0 {
+		t = time.Now().Add(c.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (t.IsZero() || d.Before(t)) {
+		t = d
+	}
+	set(t)
+	canceled := make(chan struct{})
+	stopCancel := context.AfterFunc(ctx, func() {
+		set(aLongTimeAgo)
+		close(canceled)
+	})
+	return func() {
+		if !stopCancel() {
+			<-canceled
+		}
+		set(time.Time{})
+	}
+}
+
+// aLongTimeAgo is a non-zero time, far in the past, used for immediate
+// cancellation of network operations.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// contextError returns ctx.Err() if err is non-nil and ctx is done,
+// so callers see context.Canceled or context.DeadlineExceeded rather
+// than the underlying network timeout error. Otherwise it returns err.
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
 }
 
 // writeDataUnit writes x to w.
@@ -134,6 +568,22 @@ func writeDataUnit(w io.Writer, x []byte) error {
 	return err
 }
 
+// readDataUnit reads a single EPP data unit from r and scans it into a Response.
+// If the data unit was read but could not be scanned, it returns both
+// the partially scanned Response and an error.
+func readDataUnit(r io.Reader) (*Response, error) {
+	n, err := readDataUnitHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	p := make([]byte, n)
+	_, err = io.ReadFull(r, p)
+	if err != nil {
+		return nil, err
+	}
+	return scanDataUnit(p)
+}
+
 // readDataUnitHeader reads a single EPP data unit header from r, returning the payload size or an error.
 // An EPP data unit is prefixed with 32-bit header specifying the total size
 // of the data unit (message + 4 byte header), in network (big-endian) order.
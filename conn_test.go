@@ -2,9 +2,13 @@ package epp
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"net"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
@@ -68,6 +72,281 @@ func TestNewConn(t *testing.T) {
 	st.Expect(t, err, nil)
 }
 
+func TestConnContextDeadline(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		// Respond with greeting
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Read check request, but never respond
+		_, err = readDataUnitHeader(conn)
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	dcr, err := c.CheckDomainContext(ctx, "example.com")
+	st.Expect(t, err, context.DeadlineExceeded)
+	st.Expect(t, dcr, (*DomainCheckResponse)(nil))
+	st.Expect(t, time.Since(start) < time.Second, true)
+	c.Conn.Close()
+}
+
+func TestSetDeadlineStop(t *testing.T) {
+	var m sync.Mutex
+	var deadlines []time.Time
+	canceling, release := make(chan struct{}), make(chan struct{})
+	set := func(d time.Time) error {
+		if d.Equal(aLongTimeAgo) {
+			close(canceling)
+			<-release
+		}
+		m.Lock()
+		defer m.Unlock()
+		deadlines = append(deadlines, d)
+		return nil
+	}
+	c := &Conn{}
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := c.setDeadline(ctx, set)
+	cancel()
+	<-canceling
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("stop returned before the cancellation finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+	// The deadline is cleared after the cancellation.
+	st.Expect(t, deadlines, []time.Time{{}, aLongTimeAgo, {}})
+}
+
+func TestConnPipelining(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Read both check requests, then respond in reverse order
+		var ids, names []string
+		for i := 0; i < 2; i++ {
+			x, err := readTestRequest(conn)
+			st.Assert(t, err, nil)
+			ids = append(ids, testElement(x, "clTRID"))
+			names = append(names, testElement(x, "domain:name"))
+		}
+		for i := 1; i >= 0; i-- {
+			err = writeDataUnit(conn, []byte(testXMLCheckResponse(names[i], ids[i])))
+			st.Assert(t, err, nil)
+		}
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	var wg sync.WaitGroup
+	for _, domain := range []string{"one.example", "two.example"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dcr, err := c.CheckDomain(domain)
+			st.Expect(t, err, nil)
+			st.Expect(t, len(dcr.Checks), 1)
+			st.Expect(t, dcr.Checks[0].Domain, domain)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConnScanError(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Read both check requests, then respond to the second with a
+		// response that fails to scan before its <trID>
+		var ids, names []string
+		for i := 0; i < 2; i++ {
+			x, err := readTestRequest(conn)
+			st.Assert(t, err, nil)
+			ids = append(ids, testElement(x, "clTRID"))
+			names = append(names, testElement(x, "domain:name"))
+		}
+		for i := 1; i >= 0; i-- {
+			x := testXMLCheckResponse(names[i], ids[i])
+			if names[i] == "bad.example" {
+				x = testXMLBadDateResponse(ids[i])
+			}
+			err = writeDataUnit(conn, []byte(x))
+			st.Assert(t, err, nil)
+		}
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	var wg sync.WaitGroup
+	for _, domain := range []string{"good.example", "bad.example"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.CheckDomain(domain)
+			st.Expect(t, err != nil, domain == "bad.example")
+		}()
+	}
+	wg.Wait()
+	st.Expect(t, c.closed(), false)
+}
+
+func TestConnUnknownTransaction(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		_, err = readTestRequest(conn)
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLCheckResponse("example.com", "UNKNOWN-1")))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	_, err = c.CheckDomain("example.com")
+	st.Expect(t, err, errUnknownTransaction)
+	st.Expect(t, c.closed(), true)
+}
+
+func TestConnAbandonedEviction(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Never respond to the first check request.
+		_, err = readTestRequest(conn)
+		st.Assert(t, err, nil)
+		x, err := readTestRequest(conn)
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLCheckResponse("two.example", testElement(x, "clTRID"))))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.CheckDomainContext(ctx, "one.example")
+	st.Expect(t, err, context.DeadlineExceeded)
+	c.mPending.Lock()
+	st.Expect(t, len(c.abandoned), 1)
+	c.mPending.Unlock()
+
+	_, err = c.CheckDomain("two.example")
+	st.Expect(t, err, nil)
+	c.mPending.Lock()
+	st.Expect(t, len(c.abandoned), 0)
+	c.mPending.Unlock()
+}
+
+func TestClientTransactionID(t *testing.T) {
+	st.Expect(t, clientTransactionID([]byte(testXMLBadDateResponse("ABC-12345"))), "ABC-12345")
+	st.Expect(t, clientTransactionID([]byte(testXMLGreeting)), "")
+}
+
+func TestWithClientTransactionID(t *testing.T) {
+	x := withClientTransactionID(xmlLogout, "ABC-12345")
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><logout/><clTRID>ABC-12345</clTRID></command></epp>`)
+
+	// Non-commands are not modified.
+	x = withClientTransactionID(xmlHello, "ABC-12345")
+	st.Expect(t, string(x), string(xmlHello))
+}
+
+// readTestRequest reads a single EPP data unit from r.
+func readTestRequest(r io.Reader) (string, error) {
+	n, err := readDataUnitHeader(r)
+	if err != nil {
+		return "", err
+	}
+	p := make([]byte, n)
+	_, err = io.ReadFull(r, p)
+	return string(p), err
+}
+
+// testElement returns the text content of the first element named name in x.
+func testElement(x, name string) string {
+	_, v, _ := strings.Cut(x, "<"+name+">")
+	v, _, _ = strings.Cut(v, "</"+name+">")
+	return v
+}
+
+func testXMLCheckResponse(domain, clTRID string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<domain:chkData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:cd>
+					<domain:name avail="1">` + domain + `</domain:name>
+				</domain:cd>
+			</domain:chkData>
+		</resData>
+		<trID>
+			<clTRID>` + clTRID + `</clTRID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+}
+
 func TestDeleteRange(t *testing.T) {
 	v := deleteRange([]byte(`<foo><bar><baz></baz></bar></foo>`), []byte(`<baz`), []byte(`</baz>`))
 	st.Expect(t, string(v), `<foo><bar></bar></foo>`)
@@ -95,3 +374,24 @@ func deleteRange(s, pfx, sfx []byte) []byte {
 	copy(s[start:size], s[end:])
 	return s[:size]
 }
+
+func testXMLBadDateResponse(clTRID string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<domain:creData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>bad.example</domain:name>
+				<domain:crDate>yesterday</domain:crDate>
+			</domain:creData>
+		</resData>
+		<trID>
+			<clTRID>` + clTRID + `</clTRID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+}
@@ -0,0 +1,757 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+var (
+	errMissingContact     = errors.New("epp: missing contact ID")
+	errEmptyContactUpdate = errors.New("epp: contact update requires at least one add, rem or chg element")
+)
+
+// Postal info types, used as the type attribute of <contact:postalInfo>.
+// https://tools.ietf.org/html/rfc5733#section-2.3
+const (
+	PostalInfoInternational = "int" // 7-bit US-ASCII
+	PostalInfoLocal         = "loc" // localized, may contain UTF-8
+)
+
+// PostalInfo represents a <contact:postalInfo> element.
+// https://tools.ietf.org/html/rfc5733#section-2.3
+type PostalInfo struct {
+	Type   string   // PostalInfoInternational or PostalInfoLocal
+	Name   string   // <contact:name>
+	Org    string   // <contact:org>, optional
+	Street []string // <contact:street>, up to 3 lines
+	City   string   // <contact:city>
+	SP     string   // <contact:sp>, state or province, optional
+	PC     string   // <contact:pc>, postal code, optional
+	CC     string   // <contact:cc>, two-letter country code
+}
+
+// Phone represents a telephone number in <contact:voice> or <contact:fax>.
+// https://tools.ietf.org/html/rfc5733#section-2.5
+type Phone struct {
+	Number string // E.164 number, e.g. +1.7035555555
+	Ext    string // x attribute, optional extension
+}
+
+// IsZero reports whether p is unset.
+func (p Phone) IsZero() bool {
+	return p.Number == ""
+}
+
+// Disclose represents a <contact:disclose> element, identifying contact
+// elements that the server should (Flag true) or should not (Flag false)
+// disclose to third parties.
+// https://tools.ietf.org/html/rfc5733#section-2.9
+type Disclose struct {
+	Flag  bool     // flag attribute
+	Name  []string // postal info types of <contact:name> elements
+	Org   []string // postal info types of <contact:org> elements
+	Addr  []string // postal info types of <contact:addr> elements
+	Voice bool     // <contact:voice/>
+	Fax   bool     // <contact:fax/>
+	Email bool     // <contact:email/>
+}
+
+// ContactCheck queries the EPP server for the availability status of one or more contact IDs.
+// https://tools.ietf.org/html/rfc5733#section-3.1.1
+func (c *Conn) ContactCheck(ids ...string) (*ContactCheckResponse, error) {
+	return c.ContactCheckContext(context.Background(), ids...)
+}
+
+// ContactCheckContext is like ContactCheck, but aborts if ctx is done before the server responds.
+func (c *Conn) ContactCheckContext(ctx context.Context, ids ...string) (*ContactCheckResponse, error) {
+	x, err := encodeContactCheck(ids)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ContactCheck, nil
+}
+
+// ContactInfo retrieves info for a contact. The authInfo password is
+// optional, and allows retrieving info for a contact sponsored by another client.
+// https://tools.ietf.org/html/rfc5733#section-3.1.2
+func (c *Conn) ContactInfo(id, authInfo string) (*ContactInfoResponse, error) {
+	return c.ContactInfoContext(context.Background(), id, authInfo)
+}
+
+// ContactInfoContext is like ContactInfo, but aborts if ctx is done before the server responds.
+func (c *Conn) ContactInfoContext(ctx context.Context, id, authInfo string) (*ContactInfoResponse, error) {
+	x, err := encodeContactInfo(id, authInfo)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ContactInfo, nil
+}
+
+// ContactCreateRequest represents an EPP request to create a contact.
+// https://tools.ietf.org/html/rfc5733#section-3.2.1
+type ContactCreateRequest struct {
+	ID         string       // <contact:id>
+	PostalInfo []PostalInfo // <contact:postalInfo>, one or two (int and loc)
+	Voice      Phone        // <contact:voice>, optional
+	Fax        Phone        // <contact:fax>, optional
+	Email      string       // <contact:email>
+	AuthInfo   string       // <contact:authInfo><contact:pw>
+	Disclose   *Disclose    // <contact:disclose>, optional
+}
+
+// ContactCreate creates a contact.
+// https://tools.ietf.org/html/rfc5733#section-3.2.1
+func (c *Conn) ContactCreate(req *ContactCreateRequest) (*ContactCreateResponse, error) {
+	return c.ContactCreateContext(context.Background(), req)
+}
+
+// ContactCreateContext is like ContactCreate, but aborts if ctx is done before the server responds.
+func (c *Conn) ContactCreateContext(ctx context.Context, req *ContactCreateRequest) (*ContactCreateResponse, error) {
+	x, err := encodeContactCreate(req)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ContactCreate, nil
+}
+
+// ContactUpdateRequest represents an EPP request to update a contact.
+// At least one of Add, Remove or Change must be non-empty.
+// https://tools.ietf.org/html/rfc5733#section-3.2.5
+type ContactUpdateRequest struct {
+	ID     string        // <contact:id>
+	Add    Status        // <contact:add><contact:status>
+	Remove Status        // <contact:rem><contact:status>
+	Change ContactChange // <contact:chg>
+}
+
+// ContactChange represents the attributes changed in a <contact:chg> element.
+// Empty fields are left unchanged. In each PostalInfo, the name and org are
+// changed if set, and the address is replaced if any address field is set.
+type ContactChange struct {
+	PostalInfo []PostalInfo // <contact:postalInfo>
+	Voice      Phone        // <contact:voice>
+	Fax        Phone        // <contact:fax>
+	Email      string       // <contact:email>
+	AuthInfo   string       // <contact:authInfo><contact:pw>
+	Disclose   *Disclose    // <contact:disclose>
+}
+
+// IsZero reports whether chg is empty.
+func (chg *ContactChange) IsZero() bool {
+	return len(chg.PostalInfo) == 0 && chg.Voice.IsZero() && chg.Fax.IsZero() &&
+		chg.Email == "" && chg.AuthInfo == "" && chg.Disclose == nil
+}
+
+// ContactUpdate updates a contact.
+// https://tools.ietf.org/html/rfc5733#section-3.2.5
+func (c *Conn) ContactUpdate(req *ContactUpdateRequest) (Result, error) {
+	return c.ContactUpdateContext(context.Background(), req)
+}
+
+// ContactUpdateContext is like ContactUpdate, but aborts if ctx is done before the server responds.
+func (c *Conn) ContactUpdateContext(ctx context.Context, req *ContactUpdateRequest) (Result, error) {
+	x, err := encodeContactUpdate(req)
+	if err != nil {
+		return Result{}, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return Result{}, err
+	}
+	return res.Result, nil
+}
+
+// ContactDelete deletes a contact. It returns pending == true if the server
+// accepted the request but the deletion is pending (result code 1001).
+// https://tools.ietf.org/html/rfc5733#section-3.2.2
+func (c *Conn) ContactDelete(id string) (pending bool, err error) {
+	return c.ContactDeleteContext(context.Background(), id)
+}
+
+// ContactDeleteContext is like ContactDelete, but aborts if ctx is done before the server responds.
+func (c *Conn) ContactDeleteContext(ctx context.Context, id string) (pending bool, err error) {
+	x, err := encodeContactDelete(id)
+	if err != nil {
+		return false, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	return res.Result.Code == ResultSuccessPending, nil
+}
+
+// ContactTransfer performs transfer operation op on a contact.
+// The authInfo password is required to request a transfer, and optional otherwise.
+// https://tools.ietf.org/html/rfc5733#section-3.2.4
+func (c *Conn) ContactTransfer(op, id, authInfo string) (*ContactTransferResponse, error) {
+	return c.ContactTransferContext(context.Background(), op, id, authInfo)
+}
+
+// ContactTransferContext is like ContactTransfer, but aborts if ctx is done before the server responds.
+func (c *Conn) ContactTransferContext(ctx context.Context, op, id, authInfo string) (*ContactTransferResponse, error) {
+	x, err := encodeContactTransfer(op, id, authInfo)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ContactTransfer, nil
+}
+
+func encodeContactCheck(ids []string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<check><contact:check xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	for _, id := range ids {
+		err := encodeContactID(buf, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString(`</contact:check></check>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeContactInfo(id, authInfo string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<info><contact:info xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	err := encodeContactID(buf, id)
+	if err != nil {
+		return nil, err
+	}
+	if authInfo != "" {
+		encodeContactAuthInfo(buf, authInfo)
+	}
+	buf.WriteString(`</contact:info></info>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeContactCreate(req *ContactCreateRequest) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<create><contact:create xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	err := encodeContactID(buf, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range req.PostalInfo {
+		encodePostalInfo(buf, &req.PostalInfo[i])
+	}
+	encodePhone(buf, "contact:voice", req.Voice)
+	encodePhone(buf, "contact:fax", req.Fax)
+	buf.WriteString(`<contact:email>`)
+	xml.EscapeText(buf, []byte(req.Email))
+	buf.WriteString(`</contact:email>`)
+	encodeContactAuthInfo(buf, req.AuthInfo)
+	if req.Disclose != nil {
+		encodeDisclose(buf, req.Disclose)
+	}
+	buf.WriteString(`</contact:create></create>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeContactUpdate(req *ContactUpdateRequest) ([]byte, error) {
+	if req.Add == StatusUnknown && req.Remove == StatusUnknown && req.Change.IsZero() {
+		return nil, errEmptyContactUpdate
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<update><contact:update xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	err := encodeContactID(buf, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Add != StatusUnknown {
+		buf.WriteString(`<contact:add>`)
+		encodeContactStatus(buf, req.Add)
+		buf.WriteString(`</contact:add>`)
+	}
+	if req.Remove != StatusUnknown {
+		buf.WriteString(`<contact:rem>`)
+		encodeContactStatus(buf, req.Remove)
+		buf.WriteString(`</contact:rem>`)
+	}
+	if chg := &req.Change; !chg.IsZero() {
+		buf.WriteString(`<contact:chg>`)
+		for i := range chg.PostalInfo {
+			encodePostalInfoChange(buf, &chg.PostalInfo[i])
+		}
+		encodePhone(buf, "contact:voice", chg.Voice)
+		encodePhone(buf, "contact:fax", chg.Fax)
+		if chg.Email != "" {
+			buf.WriteString(`<contact:email>`)
+			xml.EscapeText(buf, []byte(chg.Email))
+			buf.WriteString(`</contact:email>`)
+		}
+		if chg.AuthInfo != "" {
+			encodeContactAuthInfo(buf, chg.AuthInfo)
+		}
+		if chg.Disclose != nil {
+			encodeDisclose(buf, chg.Disclose)
+		}
+		buf.WriteString(`</contact:chg>`)
+	}
+	buf.WriteString(`</contact:update></update>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeContactDelete(id string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<delete><contact:delete xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	err := encodeContactID(buf, id)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`</contact:delete></delete>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeContactTransfer(op, id, authInfo string) ([]byte, error) {
+	if !validTransferOp(op) {
+		return nil, errInvalidTransferOp
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<transfer op="`)
+	buf.WriteString(op)
+	buf.WriteString(`"><contact:transfer xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	err := encodeContactID(buf, id)
+	if err != nil {
+		return nil, err
+	}
+	if authInfo != "" {
+		encodeContactAuthInfo(buf, authInfo)
+	}
+	buf.WriteString(`</contact:transfer></transfer>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// encodeContactID writes a <contact:id> element for id to buf.
+func encodeContactID(buf *bytes.Buffer, id string) error {
+	if id == "" {
+		return errMissingContact
+	}
+	buf.WriteString(`<contact:id>`)
+	xml.EscapeText(buf, []byte(id))
+	buf.WriteString(`</contact:id>`)
+	return nil
+}
+
+// encodeContactAuthInfo writes a <contact:authInfo> element containing password to buf.
+func encodeContactAuthInfo(buf *bytes.Buffer, password string) {
+	buf.WriteString(`<contact:authInfo><contact:pw>`)
+	xml.EscapeText(buf, []byte(password))
+	buf.WriteString(`</contact:pw></contact:authInfo>`)
+}
+
+// encodeContactStatus writes a <contact:status> element for each bit set in s to buf.
+func encodeContactStatus(buf *bytes.Buffer, s Status) {
+	for _, status := range s.Strings() {
+		buf.WriteString(`<contact:status s="`)
+		buf.WriteString(status)
+		buf.WriteString(`"/>`)
+	}
+}
+
+// encodePostalInfo writes a <contact:postalInfo> element for pi to buf.
+func encodePostalInfo(buf *bytes.Buffer, pi *PostalInfo) {
+	buf.WriteString(`<contact:postalInfo type="`)
+	xml.EscapeText(buf, []byte(pi.Type))
+	buf.WriteString(`"><contact:name>`)
+	xml.EscapeText(buf, []byte(pi.Name))
+	buf.WriteString(`</contact:name>`)
+	encodeOrg(buf, pi.Org)
+	encodeAddr(buf, pi)
+	buf.WriteString(`</contact:postalInfo>`)
+}
+
+// encodePostalInfoChange writes a <contact:postalInfo> element for pi
+// in a <contact:chg> element to buf, omitting fields that are not set.
+// https://tools.ietf.org/html/rfc5733#section-3.2.5
+func encodePostalInfoChange(buf *bytes.Buffer, pi *PostalInfo) {
+	buf.WriteString(`<contact:postalInfo type="`)
+	xml.EscapeText(buf, []byte(pi.Type))
+	buf.WriteString(`">`)
+	if pi.Name != "" {
+		buf.WriteString(`<contact:name>`)
+		xml.EscapeText(buf, []byte(pi.Name))
+		buf.WriteString(`</contact:name>`)
+	}
+	encodeOrg(buf, pi.Org)
+	if len(pi.Street) > 0 || pi.City != "" || pi.SP != "" || pi.PC != "" || pi.CC != "" {
+		encodeAddr(buf, pi)
+	}
+	buf.WriteString(`</contact:postalInfo>`)
+}
+
+// encodeOrg writes a <contact:org> element to buf, if org is set.
+func encodeOrg(buf *bytes.Buffer, org string) {
+	if org == "" {
+		return
+	}
+	buf.WriteString(`<contact:org>`)
+	xml.EscapeText(buf, []byte(org))
+	buf.WriteString(`</contact:org>`)
+}
+
+// encodeAddr writes a <contact:addr> element for the address in pi to buf.
+func encodeAddr(buf *bytes.Buffer, pi *PostalInfo) {
+	buf.WriteString(`<contact:addr>`)
+	for _, street := range pi.Street {
+		buf.WriteString(`<contact:street>`)
+		xml.EscapeText(buf, []byte(street))
+		buf.WriteString(`</contact:street>`)
+	}
+	buf.WriteString(`<contact:city>`)
+	xml.EscapeText(buf, []byte(pi.City))
+	buf.WriteString(`</contact:city>`)
+	if pi.SP != "" {
+		buf.WriteString(`<contact:sp>`)
+		xml.EscapeText(buf, []byte(pi.SP))
+		buf.WriteString(`</contact:sp>`)
+	}
+	if pi.PC != "" {
+		buf.WriteString(`<contact:pc>`)
+		xml.EscapeText(buf, []byte(pi.PC))
+		buf.WriteString(`</contact:pc>`)
+	}
+	buf.WriteString(`<contact:cc>`)
+	xml.EscapeText(buf, []byte(pi.CC))
+	buf.WriteString(`</contact:cc></contact:addr>`)
+}
+
+// encodePhone writes a phone number element with the given name
+// (e.g. "contact:voice") to buf, if p is set.
+func encodePhone(buf *bytes.Buffer, name string, p Phone) {
+	if p.IsZero() {
+		return
+	}
+	buf.WriteString(`<` + name)
+	if p.Ext != "" {
+		buf.WriteString(` x="`)
+		xml.EscapeText(buf, []byte(p.Ext))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(`>`)
+	xml.EscapeText(buf, []byte(p.Number))
+	buf.WriteString(`</` + name + `>`)
+}
+
+// encodeDisclose writes a <contact:disclose> element for d to buf.
+func encodeDisclose(buf *bytes.Buffer, d *Disclose) {
+	if d.Flag {
+		buf.WriteString(`<contact:disclose flag="1">`)
+	} else {
+		buf.WriteString(`<contact:disclose flag="0">`)
+	}
+	encodeDiscloseTypes(buf, "name", d.Name)
+	encodeDiscloseTypes(buf, "org", d.Org)
+	encodeDiscloseTypes(buf, "addr", d.Addr)
+	if d.Voice {
+		buf.WriteString(`<contact:voice/>`)
+	}
+	if d.Fax {
+		buf.WriteString(`<contact:fax/>`)
+	}
+	if d.Email {
+		buf.WriteString(`<contact:email/>`)
+	}
+	buf.WriteString(`</contact:disclose>`)
+}
+
+// encodeDiscloseTypes writes an empty <contact:name>, <contact:org> or
+// <contact:addr> element to buf for each postal info type in types.
+func encodeDiscloseTypes(buf *bytes.Buffer, name string, types []string) {
+	for _, typ := range types {
+		buf.WriteString(`<contact:` + name + ` type="`)
+		xml.EscapeText(buf, []byte(typ))
+		buf.WriteString(`"/>`)
+	}
+}
+
+// ContactCheckResponse represents an EPP <response> for a contact check.
+// https://tools.ietf.org/html/rfc5733#section-3.1.1
+type ContactCheckResponse struct {
+	Checks []ContactCheck
+}
+
+// ContactCheck represents a single contact ID in an EPP <contact:chkData>.
+type ContactCheck struct {
+	ID        string
+	Reason    string
+	Available bool
+}
+
+// ContactInfoResponse represents an EPP response for a contact info request.
+// https://tools.ietf.org/html/rfc5733#section-3.1.2
+type ContactInfoResponse struct {
+	ID         string       // <contact:id>
+	ROID       string       // <contact:roid>
+	Status     []string     // <contact:status>
+	PostalInfo []PostalInfo // <contact:postalInfo>
+	Voice      Phone        // <contact:voice>
+	Fax        Phone        // <contact:fax>
+	Email      string       // <contact:email>
+	ClID       string       // <contact:clID>
+	CrID       string       // <contact:crID>
+	UpID       string       // <contact:upID>
+	CrDate     time.Time    // <contact:crDate>
+	UpDate     time.Time    // <contact:upDate>
+	TrDate     time.Time    // <contact:trDate>
+	AuthInfo   string       // <contact:authInfo><contact:pw>
+	Disclose   *Disclose    // <contact:disclose>
+}
+
+// ContactCreateResponse represents an EPP response for a contact create request.
+// https://tools.ietf.org/html/rfc5733#section-3.2.1
+type ContactCreateResponse struct {
+	ID     string    // <contact:id>
+	CrDate time.Time // <contact:crDate>
+}
+
+// ContactTransferResponse represents an EPP response for a contact transfer request.
+// https://tools.ietf.org/html/rfc5733#section-3.2.4
+type ContactTransferResponse struct {
+	ID     string    // <contact:id>
+	Status string    // <contact:trStatus>
+	ReID   string    // <contact:reID>
+	ReDate time.Time // <contact:reDate>
+	AcID   string    // <contact:acID>
+	AcDate time.Time // <contact:acDate>
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjContact + " chkData"
+	scanResponse.MustHandleStartElement(path+">cd", func(c *xx.Context) error {
+		ccr := &c.Value.(*Response).ContactCheck
+		ccr.Checks = append(ccr.Checks, ContactCheck{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>id", func(c *xx.Context) error {
+		checks := c.Value.(*Response).ContactCheck.Checks
+		check := &checks[len(checks)-1]
+		check.ID = string(c.CharData)
+		check.Available = c.AttrBool("", "avail")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>reason", func(c *xx.Context) error {
+		checks := c.Value.(*Response).ContactCheck.Checks
+		check := &checks[len(checks)-1]
+		check.Reason = string(c.CharData)
+		return nil
+	})
+
+	path = "epp > response > resData > " + ObjContact + " infData"
+	scanResponse.MustHandleCharData(path+">id", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.ID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">roid", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.ROID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">status", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.Status = append(cir.Status, c.Attr("", "s"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">postalInfo", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.PostalInfo = append(cir.PostalInfo, PostalInfo{Type: c.Attr("", "type")})
+		return nil
+	})
+	scanPostalInfo := func(name string, f func(pi *PostalInfo, v string)) {
+		scanResponse.MustHandleCharData(path+">postalInfo>"+name, func(c *xx.Context) error {
+			pis := c.Value.(*Response).ContactInfo.PostalInfo
+			f(&pis[len(pis)-1], string(c.CharData))
+			return nil
+		})
+	}
+	scanPostalInfo("name", func(pi *PostalInfo, v string) { pi.Name = v })
+	scanPostalInfo("org", func(pi *PostalInfo, v string) { pi.Org = v })
+	scanPostalInfo("addr>street", func(pi *PostalInfo, v string) { pi.Street = append(pi.Street, v) })
+	scanPostalInfo("addr>city", func(pi *PostalInfo, v string) { pi.City = v })
+	scanPostalInfo("addr>sp", func(pi *PostalInfo, v string) { pi.SP = v })
+	scanPostalInfo("addr>pc", func(pi *PostalInfo, v string) { pi.PC = v })
+	scanPostalInfo("addr>cc", func(pi *PostalInfo, v string) { pi.CC = v })
+	scanResponse.MustHandleCharData(path+">voice", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.Voice = Phone{Number: string(c.CharData), Ext: c.Attr("", "x")}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">fax", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.Fax = Phone{Number: string(c.CharData), Ext: c.Attr("", "x")}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">email", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.Email = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">clID", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.ClID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crID", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.CrID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">upID", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.UpID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		var err error
+		cir.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">upDate", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		var err error
+		cir.UpDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">trDate", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		var err error
+		cir.TrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">authInfo>pw", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.AuthInfo = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfo
+		cir.Disclose = &Disclose{Flag: c.AttrBool("", "flag")}
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>name", func(c *xx.Context) error {
+		d := c.Value.(*Response).ContactInfo.Disclose
+		d.Name = append(d.Name, c.Attr("", "type"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>org", func(c *xx.Context) error {
+		d := c.Value.(*Response).ContactInfo.Disclose
+		d.Org = append(d.Org, c.Attr("", "type"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>addr", func(c *xx.Context) error {
+		d := c.Value.(*Response).ContactInfo.Disclose
+		d.Addr = append(d.Addr, c.Attr("", "type"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>voice", func(c *xx.Context) error {
+		c.Value.(*Response).ContactInfo.Disclose.Voice = true
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>fax", func(c *xx.Context) error {
+		c.Value.(*Response).ContactInfo.Disclose.Fax = true
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>email", func(c *xx.Context) error {
+		c.Value.(*Response).ContactInfo.Disclose.Email = true
+		return nil
+	})
+
+	path = "epp > response > resData > " + ObjContact + " creData"
+	scanResponse.MustHandleCharData(path+">id", func(c *xx.Context) error {
+		ccr := &c.Value.(*Response).ContactCreate
+		ccr.ID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
+		ccr := &c.Value.(*Response).ContactCreate
+		var err error
+		ccr.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+
+	path = "epp > response > resData > " + ObjContact + " trnData"
+	scanResponse.MustHandleCharData(path+">id", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransfer
+		ctr.ID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">trStatus", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransfer
+		ctr.Status = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reID", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransfer
+		ctr.ReID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reDate", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransfer
+		var err error
+		ctr.ReDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">acID", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransfer
+		ctr.AcID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">acDate", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransfer
+		var err error
+		ctr.AcDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+}
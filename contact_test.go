@@ -0,0 +1,282 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeContactCheck(t *testing.T) {
+	x, err := encodeContactCheck([]string{"sh8013", "sah8013"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><contact:check xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:id>sah8013</contact:id></contact:check></check></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeContactCheck([]string{""})
+	st.Expect(t, err, errMissingContact)
+}
+
+func TestEncodeContactInfo(t *testing.T) {
+	x, err := encodeContactInfo("sh8013", "2fooBAR")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><contact:info xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:authInfo><contact:pw>2fooBAR</contact:pw></contact:authInfo></contact:info></info></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeContactCreate(t *testing.T) {
+	req := &ContactCreateRequest{
+		ID: "sh8013",
+		PostalInfo: []PostalInfo{{
+			Type:   PostalInfoInternational,
+			Name:   "John Doe",
+			Org:    "Example Inc.",
+			Street: []string{"123 Example Dr.", "Suite 100"},
+			City:   "Dulles",
+			SP:     "VA",
+			PC:     "20166-6503",
+			CC:     "US",
+		}},
+		Voice:    Phone{Number: "+1.7035555555", Ext: "1234"},
+		Fax:      Phone{Number: "+1.7035555556"},
+		Email:    "jdoe@example.com",
+		AuthInfo: "2fooBAR",
+		Disclose: &Disclose{Voice: true, Email: true},
+	}
+	x, err := encodeContactCreate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><contact:create xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:postalInfo type="int"><contact:name>John Doe</contact:name><contact:org>Example Inc.</contact:org><contact:addr><contact:street>123 Example Dr.</contact:street><contact:street>Suite 100</contact:street><contact:city>Dulles</contact:city><contact:sp>VA</contact:sp><contact:pc>20166-6503</contact:pc><contact:cc>US</contact:cc></contact:addr></contact:postalInfo><contact:voice x="1234">+1.7035555555</contact:voice><contact:fax>+1.7035555556</contact:fax><contact:email>jdoe@example.com</contact:email><contact:authInfo><contact:pw>2fooBAR</contact:pw></contact:authInfo><contact:disclose flag="0"><contact:voice/><contact:email/></contact:disclose></contact:create></create></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeContactCreate(&ContactCreateRequest{})
+	st.Expect(t, err, errMissingContact)
+}
+
+func TestEncodeContactUpdate(t *testing.T) {
+	req := &ContactUpdateRequest{
+		ID:  "sh8013",
+		Add: StatusClientDeleteProhibited,
+		Change: ContactChange{
+			PostalInfo: []PostalInfo{{
+				Type:   PostalInfoInternational,
+				Street: []string{"124 Example Dr.", "Suite 200"},
+				City:   "Dulles",
+				CC:     "US",
+			}},
+			Voice:    Phone{Number: "+1.7034444444"},
+			AuthInfo: "2fooBAR",
+			Disclose: &Disclose{Flag: true, Voice: true, Email: true},
+		},
+	}
+	x, err := encodeContactUpdate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><contact:update xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:add><contact:status s="clientDeleteProhibited"/></contact:add><contact:chg><contact:postalInfo type="int"><contact:addr><contact:street>124 Example Dr.</contact:street><contact:street>Suite 200</contact:street><contact:city>Dulles</contact:city><contact:cc>US</contact:cc></contact:addr></contact:postalInfo><contact:voice>+1.7034444444</contact:voice><contact:authInfo><contact:pw>2fooBAR</contact:pw></contact:authInfo><contact:disclose flag="1"><contact:voice/><contact:email/></contact:disclose></contact:chg></contact:update></update></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeContactUpdate(&ContactUpdateRequest{ID: "sh8013"})
+	st.Expect(t, err, errEmptyContactUpdate)
+}
+
+func TestEncodeContactUpdateName(t *testing.T) {
+	req := &ContactUpdateRequest{
+		ID: "sh8013",
+		Change: ContactChange{
+			PostalInfo: []PostalInfo{{Type: PostalInfoInternational, Name: "New Name"}},
+		},
+	}
+	x, err := encodeContactUpdate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><contact:update xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:chg><contact:postalInfo type="int"><contact:name>New Name</contact:name></contact:postalInfo></contact:chg></contact:update></update></command></epp>`)
+}
+
+func TestEncodeDisclose(t *testing.T) {
+	var buf bytes.Buffer
+	encodeDisclose(&buf, &Disclose{Name: []string{PostalInfoLocal}, Addr: []string{`int"/><evil/`}})
+	st.Expect(t, buf.String(), `<contact:disclose flag="0"><contact:name type="loc"/><contact:addr type="int&#34;/&gt;&lt;evil/"/></contact:disclose>`)
+}
+
+func TestEncodeContactDeleteAndTransfer(t *testing.T) {
+	x, err := encodeContactDelete("sh8013")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><delete><contact:delete xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id></contact:delete></delete></command></epp>`)
+
+	x, err = encodeContactTransfer(TransferRequest, "sh8013", "2fooBAR")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="request"><contact:transfer xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:authInfo><contact:pw>2fooBAR</contact:pw></contact:authInfo></contact:transfer></transfer></command></epp>`)
+
+	_, err = encodeContactTransfer("steal", "sh8013", "")
+	st.Expect(t, err, errInvalidTransferOp)
+}
+
+func TestScanContactCheckResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<contact:chkData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+				<contact:cd>
+					<contact:id avail="1">sh8013</contact:id>
+				</contact:cd>
+				<contact:cd>
+					<contact:id avail="0">sah8013</contact:id>
+					<contact:reason>In use</contact:reason>
+				</contact:cd>
+			</contact:chkData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	ccr := &res.ContactCheck
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, ccr.Checks, []ContactCheck{
+		{ID: "sh8013", Available: true},
+		{ID: "sah8013", Reason: "In use"},
+	})
+}
+
+func TestScanContactInfoResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<contact:infData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+				<contact:id>sh8013</contact:id>
+				<contact:roid>SH8013-REP</contact:roid>
+				<contact:status s="linked"/>
+				<contact:status s="clientDeleteProhibited"/>
+				<contact:postalInfo type="int">
+					<contact:name>John Doe</contact:name>
+					<contact:org>Example Inc.</contact:org>
+					<contact:addr>
+						<contact:street>123 Example Dr.</contact:street>
+						<contact:street>Suite 100</contact:street>
+						<contact:city>Dulles</contact:city>
+						<contact:sp>VA</contact:sp>
+						<contact:pc>20166-6503</contact:pc>
+						<contact:cc>US</contact:cc>
+					</contact:addr>
+				</contact:postalInfo>
+				<contact:voice x="1234">+1.7035555555</contact:voice>
+				<contact:fax>+1.7035555556</contact:fax>
+				<contact:email>jdoe@example.com</contact:email>
+				<contact:clID>ClientY</contact:clID>
+				<contact:crID>ClientX</contact:crID>
+				<contact:crDate>1999-04-03T22:00:00.0Z</contact:crDate>
+				<contact:upID>ClientX</contact:upID>
+				<contact:upDate>1999-12-03T09:00:00.0Z</contact:upDate>
+				<contact:trDate>2000-04-08T09:00:00.0Z</contact:trDate>
+				<contact:authInfo>
+					<contact:pw>2fooBAR</contact:pw>
+				</contact:authInfo>
+				<contact:disclose flag="0">
+					<contact:name type="int"/>
+					<contact:voice/>
+					<contact:email/>
+				</contact:disclose>
+			</contact:infData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	cir := &res.ContactInfo
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, cir.ID, "sh8013")
+	st.Expect(t, cir.ROID, "SH8013-REP")
+	st.Expect(t, cir.Status, []string{"linked", "clientDeleteProhibited"})
+	st.Expect(t, cir.PostalInfo, []PostalInfo{{
+		Type:   PostalInfoInternational,
+		Name:   "John Doe",
+		Org:    "Example Inc.",
+		Street: []string{"123 Example Dr.", "Suite 100"},
+		City:   "Dulles",
+		SP:     "VA",
+		PC:     "20166-6503",
+		CC:     "US",
+	}})
+	st.Expect(t, cir.Voice, Phone{Number: "+1.7035555555", Ext: "1234"})
+	st.Expect(t, cir.Fax, Phone{Number: "+1.7035555556"})
+	st.Expect(t, cir.Email, "jdoe@example.com")
+	st.Expect(t, cir.ClID, "ClientY")
+	st.Expect(t, cir.CrID, "ClientX")
+	st.Expect(t, cir.UpID, "ClientX")
+	st.Expect(t, cir.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, cir.UpDate, time.Date(1999, 12, 3, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, cir.TrDate, time.Date(2000, 4, 8, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, cir.AuthInfo, "2fooBAR")
+	st.Expect(t, cir.Disclose, &Disclose{Name: []string{"int"}, Voice: true, Email: true})
+}
+
+func TestScanContactTransferResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1001">
+			<msg>Command completed successfully; action pending</msg>
+		</result>
+		<resData>
+			<contact:trnData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+				<contact:id>sh8013</contact:id>
+				<contact:trStatus>pending</contact:trStatus>
+				<contact:reID>ClientX</contact:reID>
+				<contact:reDate>2000-06-06T22:00:00.0Z</contact:reDate>
+				<contact:acID>ClientY</contact:acID>
+				<contact:acDate>2000-06-11T22:00:00.0Z</contact:acDate>
+			</contact:trnData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	ctr := &res.ContactTransfer
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, ctr.ID, "sh8013")
+	st.Expect(t, ctr.Status, "pending")
+	st.Expect(t, ctr.ReID, "ClientX")
+	st.Expect(t, ctr.ReDate, time.Date(2000, 6, 6, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, ctr.AcID, "ClientY")
+	st.Expect(t, ctr.AcDate, time.Date(2000, 6, 11, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, res.DomainTransfer.Domain, "")
+}
@@ -0,0 +1,100 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+// DomainCreateRequest represents an EPP request to register a domain.
+// https://tools.ietf.org/html/rfc5731#section-3.2.1
+type DomainCreateRequest struct {
+	Domain      string          // <domain:name>
+	Period      Period          // <domain:period>, optional
+	Nameservers []Nameserver    // <domain:ns>, optional
+	Registrant  string          // <domain:registrant>, optional
+	Contacts    []DomainContact // <domain:contact>, optional
+	AuthInfo    string          // <domain:authInfo><domain:pw>
+}
+
+// DomainCreate registers a domain.
+// https://tools.ietf.org/html/rfc5731#section-3.2.1
+func (c *Conn) DomainCreate(req *DomainCreateRequest) (*DomainCreateResponse, error) {
+	return c.DomainCreateContext(context.Background(), req)
+}
+
+// DomainCreateContext is like DomainCreate, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainCreateContext(ctx context.Context, req *DomainCreateRequest) (*DomainCreateResponse, error) {
+	x, err := encodeDomainCreate(req)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainCreate, nil
+}
+
+func encodeDomainCreate(req *DomainCreateRequest) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	err := encodeDomainName(buf, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	err = encodePeriod(buf, req.Period)
+	if err != nil {
+		return nil, err
+	}
+	err = encodeNameservers(buf, req.Nameservers)
+	if err != nil {
+		return nil, err
+	}
+	if req.Registrant != "" {
+		buf.WriteString(`<domain:registrant>`)
+		xml.EscapeText(buf, []byte(req.Registrant))
+		buf.WriteString(`</domain:registrant>`)
+	}
+	encodeDomainContacts(buf, req.Contacts)
+	encodeDomainAuthInfo(buf, req.AuthInfo)
+	buf.WriteString(`</domain:create></create>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// DomainCreateResponse represents an EPP response for a domain create request.
+// https://tools.ietf.org/html/rfc5731#section-3.2.1
+type DomainCreateResponse struct {
+	Domain string    // <domain:name>
+	CrDate time.Time // <domain:crDate>
+	ExDate time.Time // <domain:exDate>
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjDomain + " creData"
+	scanResponse.MustHandleCharData(path+">name", func(c *xx.Context) error {
+		dcr := &c.Value.(*Response).DomainCreate
+		dcr.Domain = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
+		dcr := &c.Value.(*Response).DomainCreate
+		var err error
+		dcr.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">exDate", func(c *xx.Context) error {
+		dcr := &c.Value.(*Response).DomainCreate
+		var err error
+		dcr.ExDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+}
@@ -0,0 +1,108 @@
+package epp
+
+import (
+	"encoding/xml"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainCreate(t *testing.T) {
+	req := &DomainCreateRequest{
+		Domain: "example.com",
+		Period: Years(2),
+		Nameservers: []Nameserver{
+			{Host: "ns1.example.net"},
+			{Host: "ns2.example.net"},
+		},
+		Registrant: "jd1234",
+		Contacts: []DomainContact{
+			{Type: ContactAdmin, ID: "sh8013"},
+			{Type: ContactTech, ID: "sh8013"},
+		},
+		AuthInfo: "2fooBAR",
+	}
+	x, err := encodeDomainCreate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="y">2</domain:period><domain:ns><domain:hostObj>ns1.example.net</domain:hostObj><domain:hostObj>ns2.example.net</domain:hostObj></domain:ns><domain:registrant>jd1234</domain:registrant><domain:contact type="admin">sh8013</domain:contact><domain:contact type="tech">sh8013</domain:contact><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:create></create></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeDomainCreateHostAttr(t *testing.T) {
+	req := &DomainCreateRequest{
+		Domain: "example.com",
+		Period: Months(6),
+		Nameservers: []Nameserver{
+			{Host: "ns1.example.com", Addresses: []netip.Addr{netip.MustParseAddr("192.0.2.2"), netip.MustParseAddr("1080::8:800:200c:417a")}},
+			{Host: "ns2.example.net", Attr: true},
+		},
+		AuthInfo: "2fooBAR",
+	}
+	x, err := encodeDomainCreate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="m">6</domain:period><domain:ns><domain:hostAttr><domain:hostName>ns1.example.com</domain:hostName><domain:hostAddr ip="v4">192.0.2.2</domain:hostAddr><domain:hostAddr ip="v6">1080::8:800:200c:417a</domain:hostAddr></domain:hostAttr><domain:hostAttr><domain:hostName>ns2.example.net</domain:hostName></domain:hostAttr></domain:ns><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:create></create></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeDomainCreateErrors(t *testing.T) {
+	_, err := encodeDomainCreate(&DomainCreateRequest{})
+	st.Expect(t, err, errMissingDomain)
+
+	_, err = encodeDomainCreate(&DomainCreateRequest{Domain: "example.com", Period: Years(100)})
+	st.Expect(t, err, errInvalidPeriodValue)
+
+	_, err = encodeDomainCreate(&DomainCreateRequest{Domain: "example.com", Period: Period{Value: 1, Unit: "d"}})
+	st.Expect(t, err, errInvalidPeriodUnit)
+
+	_, err = encodeDomainCreate(&DomainCreateRequest{
+		Domain:      "example.com",
+		Nameservers: []Nameserver{{Host: "ns1.example.net"}, {Host: "ns2.example.net", Attr: true}},
+	})
+	st.Expect(t, err, errMixedNameservers)
+
+	_, err = encodeDomainCreate(&DomainCreateRequest{
+		Domain:      "example.com",
+		Nameservers: []Nameserver{{Host: "ns1.example.net", Attr: true, Addresses: []netip.Addr{{}}}},
+	})
+	st.Expect(t, err, errInvalidAddress)
+}
+
+func TestScanDomainCreateResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<domain:creData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>example.com</domain:name>
+				<domain:crDate>1999-04-03T22:00:00.0Z</domain:crDate>
+				<domain:exDate>2001-04-03T22:00:00.0Z</domain:exDate>
+			</domain:creData>
+		</resData>
+		<trID>
+			<clTRID>ABC-12345</clTRID>
+			<svTRID>54321-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	dcr := &res.DomainCreate
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, dcr.Domain, "example.com")
+	st.Expect(t, dcr.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dcr.ExDate, time.Date(2001, 4, 3, 22, 0, 0, 0, time.UTC))
+}
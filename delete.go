@@ -0,0 +1,44 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+)
+
+// DomainDelete deletes a domain. It returns pending == true if the server
+// accepted the request but the deletion is pending (result code 1001),
+// typically because the domain entered the pendingDelete or redemption
+// period, rather than being deleted immediately (result code 1000).
+// https://tools.ietf.org/html/rfc5731#section-3.2.2
+func (c *Conn) DomainDelete(domain string) (pending bool, err error) {
+	return c.DomainDeleteContext(context.Background(), domain)
+}
+
+// DomainDeleteContext is like DomainDelete, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainDeleteContext(ctx context.Context, domain string) (pending bool, err error) {
+	x, err := encodeDomainDelete(domain)
+	if err != nil {
+		return false, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	return res.Result.Code == ResultSuccessPending, nil
+}
+
+func encodeDomainDelete(domain string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<delete><domain:delete xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	err := encodeDomainName(buf, domain)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`</domain:delete></delete>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package epp
+
+import (
+	"encoding/xml"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainDelete(t *testing.T) {
+	x, err := encodeDomainDelete("example.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><delete><domain:delete xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:delete></delete></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeDomainDelete("")
+	st.Expect(t, err, errMissingDomain)
+}
+
+func TestDomainDeletePending(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		for _, code := range []string{"1000", "1001"} {
+			_, err = readTestRequest(conn)
+			st.Assert(t, err, nil)
+			err = writeDataUnit(conn, []byte(`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="`+code+`"><msg>Command completed successfully</msg></result></response></epp>`))
+			st.Assert(t, err, nil)
+		}
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	pending, err := c.DomainDelete("example.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, pending, false)
+
+	pending, err = c.DomainDelete("example.net")
+	st.Expect(t, err, nil)
+	st.Expect(t, pending, true)
+}
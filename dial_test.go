@@ -0,0 +1,133 @@
+package epp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestDialerNoTLS(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+
+	d := &Dialer{Addr: ls.Listener.Addr().String(), NoTLS: true}
+	c, err := d.Dial()
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	st.Expect(t, c.LastGreeting().ServerName, "Example EPP server epp.example.com")
+}
+
+func TestDialerTLS(t *testing.T) {
+	dir := t.TempDir()
+	cert := testCertificate(t, dir)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	st.Assert(t, err, nil)
+	ls := &localServer{Listener: ln, done: make(chan bool)}
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+
+	d := &Dialer{
+		Addr:     ln.Addr().String(),
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+		CAFile:   filepath.Join(dir, "cert.pem"),
+		Timeout:  time.Second,
+	}
+	c, err := d.Dial()
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	st.Expect(t, c.LastGreeting().ServerName, "Example EPP server epp.example.com")
+	_, ok := c.Conn.(*tls.Conn)
+	st.Expect(t, ok, true)
+}
+
+func TestDialerContextDeadline(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		// Never send a greeting
+		io.Copy(io.Discard, conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	d := &Dialer{Addr: ls.Listener.Addr().String(), NoTLS: true}
+	_, err = d.DialContext(ctx)
+	st.Expect(t, err, context.DeadlineExceeded)
+}
+
+func TestDialerMissingCA(t *testing.T) {
+	d := &Dialer{Addr: "epp.example.com", CAFile: filepath.Join(t.TempDir(), "missing.pem")}
+	_, err := d.Dial()
+	st.Expect(t, os.IsNotExist(err), true)
+}
+
+func TestDialerMissingKey(t *testing.T) {
+	d := &Dialer{Addr: "epp.example.com", CertFile: "cert.pem"}
+	_, err := d.Dial()
+	st.Expect(t, err, errMissingCertPair)
+	d = &Dialer{Addr: "epp.example.com", KeyFile: "key.pem"}
+	_, err = d.Dial()
+	st.Expect(t, err, errMissingCertPair)
+}
+
+// testCertificate writes a self-signed certificate for 127.0.0.1
+// and its private key to cert.pem and key.pem in dir.
+func testCertificate(t *testing.T, dir string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	st.Assert(t, err, nil)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	st.Assert(t, err, nil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	st.Assert(t, err, nil)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	st.Assert(t, os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o600), nil)
+	st.Assert(t, os.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0o600), nil)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	st.Assert(t, err, nil)
+	return cert
+}
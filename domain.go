@@ -0,0 +1,181 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/netip"
+	"strconv"
+)
+
+// Period represents a domain registration or renewal period.
+// https://tools.ietf.org/html/rfc5731#section-2.5
+type Period struct {
+	Value int
+	Unit  string // "y" (years) or "m" (months); defaults to years if empty
+}
+
+// Period units.
+const (
+	PeriodYears  = "y"
+	PeriodMonths = "m"
+)
+
+// Years returns a Period of n years.
+func Years(n int) Period {
+	return Period{Value: n, Unit: PeriodYears}
+}
+
+// Months returns a Period of n months.
+func Months(n int) Period {
+	return Period{Value: n, Unit: PeriodMonths}
+}
+
+// IsZero reports whether p is unset.
+func (p Period) IsZero() bool {
+	return p.Value == 0
+}
+
+// Nameserver represents a domain name server, either as a reference to a
+// host object (<domain:hostObj>) or as a host attribute (<domain:hostAttr>).
+// A Nameserver with Addresses is always a host attribute.
+// https://tools.ietf.org/html/rfc5731#section-1.1
+type Nameserver struct {
+	Host      string       // <domain:hostObj> or <domain:hostName>
+	Addresses []netip.Addr // <domain:hostAddr>
+	Attr      bool         // true if encoded as a <domain:hostAttr>
+}
+
+// IsAttr reports whether ns is encoded as a <domain:hostAttr>.
+func (ns *Nameserver) IsAttr() bool {
+	return ns.Attr || len(ns.Addresses) > 0
+}
+
+// ContactType represents the role of a contact associated with a domain.
+type ContactType string
+
+// Domain contact types.
+// https://tools.ietf.org/html/rfc5731#section-2.2
+const (
+	ContactAdmin   ContactType = "admin"
+	ContactBilling ContactType = "billing"
+	ContactTech    ContactType = "tech"
+)
+
+// DomainContact represents a contact associated with a domain, by role.
+type DomainContact struct {
+	Type ContactType // type attribute of <domain:contact>
+	ID   string      // <domain:contact>
+}
+
+var (
+	errMissingDomain      = errors.New("epp: missing domain name")
+	errMixedNameservers   = errors.New("epp: cannot mix host objects and host attributes")
+	errInvalidPeriodValue = errors.New("epp: period must be between 1 and 99")
+	errInvalidPeriodUnit  = errors.New("epp: period unit must be y or m")
+	errInvalidHosts       = errors.New("epp: hosts must be one of all, del, sub or none")
+	errInvalidAddress     = errors.New("epp: invalid IP address")
+)
+
+// encodeDomainName writes a <domain:name> element for domain to buf.
+func encodeDomainName(buf *bytes.Buffer, domain string) error {
+	if domain == "" {
+		return errMissingDomain
+	}
+	buf.WriteString(`<domain:name>`)
+	xml.EscapeText(buf, []byte(domain))
+	buf.WriteString(`</domain:name>`)
+	return nil
+}
+
+// encodePeriod writes a <domain:period> element to buf, if p is set.
+func encodePeriod(buf *bytes.Buffer, p Period) error {
+	if p.IsZero() {
+		return nil
+	}
+	if p.Value < 1 || p.Value > 99 {
+		return errInvalidPeriodValue
+	}
+	unit := p.Unit
+	switch unit {
+	case "":
+		unit = PeriodYears
+	case PeriodYears, PeriodMonths:
+	default:
+		return errInvalidPeriodUnit
+	}
+	buf.WriteString(`<domain:period unit="` + unit + `">`)
+	buf.WriteString(strconv.Itoa(p.Value))
+	buf.WriteString(`</domain:period>`)
+	return nil
+}
+
+// encodeNameservers writes a <domain:ns> element to buf, if nameservers is not empty.
+func encodeNameservers(buf *bytes.Buffer, nameservers []Nameserver) error {
+	if len(nameservers) == 0 {
+		return nil
+	}
+	attr := nameservers[0].IsAttr()
+	for i := range nameservers {
+		if nameservers[i].IsAttr() != attr {
+			return errMixedNameservers
+		}
+	}
+	buf.WriteString(`<domain:ns>`)
+	for _, ns := range nameservers {
+		if !attr {
+			buf.WriteString(`<domain:hostObj>`)
+			xml.EscapeText(buf, []byte(ns.Host))
+			buf.WriteString(`</domain:hostObj>`)
+			continue
+		}
+		buf.WriteString(`<domain:hostAttr><domain:hostName>`)
+		xml.EscapeText(buf, []byte(ns.Host))
+		buf.WriteString(`</domain:hostName>`)
+		for _, addr := range ns.Addresses {
+			err := encodeHostAddr(buf, "domain:hostAddr", addr)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteString(`</domain:hostAttr>`)
+	}
+	buf.WriteString(`</domain:ns>`)
+	return nil
+}
+
+// encodeHostAddr writes an address element with the given name
+// (e.g. "domain:hostAddr") for addr to buf.
+// It returns an error if addr is not a valid IP address.
+func encodeHostAddr(buf *bytes.Buffer, name string, addr netip.Addr) error {
+	if !addr.IsValid() {
+		return errInvalidAddress
+	}
+	addr = addr.Unmap()
+	ip := "v4"
+	if addr.Is6() {
+		ip = "v6"
+	}
+	buf.WriteString(`<` + name + ` ip="` + ip + `">`)
+	buf.WriteString(addr.String())
+	buf.WriteString(`</` + name + `>`)
+	return nil
+}
+
+// encodeDomainContacts writes a <domain:contact> element for each contact to buf.
+func encodeDomainContacts(buf *bytes.Buffer, contacts []DomainContact) {
+	for _, contact := range contacts {
+		buf.WriteString(`<domain:contact type="`)
+		xml.EscapeText(buf, []byte(contact.Type))
+		buf.WriteString(`">`)
+		xml.EscapeText(buf, []byte(contact.ID))
+		buf.WriteString(`</domain:contact>`)
+	}
+}
+
+// encodeDomainAuthInfo writes a <domain:authInfo> element containing password to buf.
+func encodeDomainAuthInfo(buf *bytes.Buffer, password string) {
+	buf.WriteString(`<domain:authInfo><domain:pw>`)
+	xml.EscapeText(buf, []byte(password))
+	buf.WriteString(`</domain:pw></domain:authInfo>`)
+}
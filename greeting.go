@@ -1,19 +1,66 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
+	"time"
 
 	"github.com/nbio/xx"
 )
 
 // Hello sends a <hello> command to request a <greeting> from the EPP server.
+// The stored Greeting is updated from the response.
 func (c *Conn) Hello() error {
-	err := c.writeRequest(xmlHello)
+	return c.HelloContext(context.Background())
+}
+
+// HelloContext is like Hello, but aborts if ctx is done before the server responds.
+func (c *Conn) HelloContext(ctx context.Context) error {
+	tx, err := c.writeRequest(ctx, xmlHello)
+	if err != nil {
+		return err
+	}
+	res, err := c.readResponse(ctx, tx)
 	if err != nil {
 		return err
 	}
-	_, err = c.readGreeting()
-	return err
+	if !res.isGreeting {
+		return errNotGreeting
+	}
+	c.m.Lock()
+	c.Greeting = res.Greeting
+	c.m.Unlock()
+	return nil
+}
+
+// Keepalive starts a goroutine that sends a <hello> to the server whenever
+// no request has been written to c for the idle duration, preventing the
+// server from closing an idle session. The goroutine stops when c is closed.
+// Keepalive should be called at most once for each Conn.
+func (c *Conn) Keepalive(idle time.Duration) {
+	go c.keepalive(idle)
+}
+
+func (c *Conn) keepalive(idle time.Duration) {
+	t := time.NewTimer(idle)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.readDone:
+			return
+		case <-t.C:
+		}
+		wait := idle - time.Since(time.Unix(0, c.lastWrite.Load()))
+		if wait <= 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), idle)
+			c.HelloContext(ctx)
+			cancel()
+			wait = idle
+		}
+		t.Reset(wait)
+	}
 }
 
 var xmlHello = []byte(xml.Header + startEPP + `<hello/>` + endEPP)
@@ -80,6 +127,7 @@ const (
 	ExtNeulevel   = "urn:ietf:params:xml:ns:neulevel"
 	ExtNeulevel10 = "urn:ietf:params:xml:ns:neulevel-1.0"
 	ExtFrnic20    = "http://www.afnic.fr/xml/epp/frnic-2.0"
+	ExtLoginSec   = "urn:ietf:params:xml:ns:epp:loginSec-1.0"
 )
 
 // ExtURNNames maps short extension names to their full URN.
@@ -102,19 +150,15 @@ var ExtURNNames = map[string]string{
 	"neulevel":         ExtNeulevel,
 	"neulevel-1.0":     ExtNeulevel10,
 	"frnic-2.0":        ExtFrnic20,
-}
-
-// TODO: check if res.Greeting is not empty.
-func (c *Conn) readGreeting() (Greeting, error) {
-	res, err := c.readResponse()
-	if err != nil {
-		return Greeting{}, err
-	}
-	return res.Greeting, nil
+	"loginSec-1.0":     ExtLoginSec,
 }
 
 func init() {
 	path := "epp>greeting"
+	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+		c.Value.(*Response).isGreeting = true
+		return nil
+	})
 	scanResponse.MustHandleCharData(path+">svID", func(c *xx.Context) error {
 		res := c.Value.(*Response)
 		res.Greeting.ServerName = string(c.CharData)
@@ -3,8 +3,12 @@ package epp
 import (
 	"bytes"
 	"encoding/xml"
+	"io"
 	"net"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
@@ -33,6 +37,158 @@ func TestHello(t *testing.T) {
 	st.Expect(t, c.Greeting.ServerName, "Example EPP server epp.example.com")
 }
 
+func TestHelloUpdatesGreeting(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Read a check and a <hello>, then answer the <hello> first
+		var check string
+		for i := 0; i < 2; i++ {
+			x, err := readTestRequest(conn)
+			st.Assert(t, err, nil)
+			if strings.Contains(x, "<check>") {
+				check = x
+			}
+		}
+		err = writeDataUnit(conn, []byte(strings.Replace(testXMLGreeting, "epp.example.com", "epp2.example.com", 1)))
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLCheckResponse("example.com", testElement(check, "clTRID"))))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dcr, err := c.CheckDomain("example.com")
+		st.Expect(t, err, nil)
+		st.Expect(t, len(dcr.Checks), 1)
+	}()
+	// Wait for the check to be sent before the <hello>
+	for {
+		c.mPending.Lock()
+		n := len(c.pending)
+		c.mPending.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	err = c.Hello()
+	st.Expect(t, err, nil)
+	wg.Wait()
+	st.Expect(t, c.LastGreeting().ServerName, "Example EPP server epp2.example.com")
+}
+
+func TestHelloIgnoresOtherResponses(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Read a check and a <hello>, then answer the check
+		// without a <clTRID> before the <hello>
+		for i := 0; i < 2; i++ {
+			_, err := readTestRequest(conn)
+			st.Assert(t, err, nil)
+		}
+		err = writeDataUnit(conn, []byte(testXMLResponse(ResultCommandSyntaxError, "")))
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(strings.Replace(testXMLGreeting, "epp.example.com", "epp2.example.com", 1)))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := c.CheckDomain("example.com")
+		st.Expect(t, err.(*Result).Code, ResultCommandSyntaxError)
+	}()
+	// Wait for the check to be sent before the <hello>
+	for {
+		c.mPending.Lock()
+		n := len(c.pending)
+		c.mPending.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	err = c.Hello()
+	st.Expect(t, err, nil)
+	wg.Wait()
+	st.Expect(t, c.LastGreeting().ServerName, "Example EPP server epp2.example.com")
+	st.Reject(t, len(c.LastGreeting().Objects), 0)
+}
+
+func TestNewConnNotGreeting(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		writeDataUnit(server, []byte(testXMLLoginResponse))
+	}()
+	_, err := NewConn(client)
+	st.Expect(t, err, errNotGreeting)
+}
+
+func TestKeepalive(t *testing.T) {
+	hello := make(chan string, 1)
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		x, err := readTestRequest(conn)
+		st.Assert(t, err, nil)
+		hello <- x
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	c.Keepalive(10 * time.Millisecond)
+	select {
+	case x := <-hello:
+		st.Expect(t, strings.Contains(x, "<hello/>"), true)
+	case <-time.After(time.Second):
+		t.Fatal("no <hello> sent")
+	}
+	// The greeting can be read while Keepalive updates it.
+	for deadline := time.Now().Add(20 * time.Millisecond); time.Now().Before(deadline); {
+		st.Assert(t, c.LastGreeting().ServerName, "Example EPP server epp.example.com")
+	}
+}
+
 func TestGreetingSupportsObject(t *testing.T) {
 	g := Greeting{}
 	st.Expect(t, g.SupportsObject(ObjDomain), false)
@@ -0,0 +1,394 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/netip"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+var (
+	errMissingHost     = errors.New("epp: missing host name")
+	errEmptyHostUpdate = errors.New("epp: host update requires at least one add, rem or chg element")
+)
+
+// HostCheck queries the EPP server for the availability status of one or more hosts.
+// https://tools.ietf.org/html/rfc5732#section-3.1.1
+func (c *Conn) HostCheck(hosts ...string) (*HostCheckResponse, error) {
+	return c.HostCheckContext(context.Background(), hosts...)
+}
+
+// HostCheckContext is like HostCheck, but aborts if ctx is done before the server responds.
+func (c *Conn) HostCheckContext(ctx context.Context, hosts ...string) (*HostCheckResponse, error) {
+	x, err := encodeHostCheck(hosts)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.HostCheck, nil
+}
+
+// HostInfo retrieves info for a host.
+// https://tools.ietf.org/html/rfc5732#section-3.1.2
+func (c *Conn) HostInfo(host string) (*HostInfoResponse, error) {
+	return c.HostInfoContext(context.Background(), host)
+}
+
+// HostInfoContext is like HostInfo, but aborts if ctx is done before the server responds.
+func (c *Conn) HostInfoContext(ctx context.Context, host string) (*HostInfoResponse, error) {
+	x, err := encodeHostCommand("info", host)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.HostInfo, nil
+}
+
+// HostCreate creates a host with optional IPv4 and IPv6 addresses.
+// Addresses are required for hosts subordinate to a domain managed by the server
+// (glue records), and must not be specified otherwise.
+// https://tools.ietf.org/html/rfc5732#section-3.2.1
+func (c *Conn) HostCreate(host string, addrs ...netip.Addr) (*HostCreateResponse, error) {
+	return c.HostCreateContext(context.Background(), host, addrs...)
+}
+
+// HostCreateContext is like HostCreate, but aborts if ctx is done before the server responds.
+func (c *Conn) HostCreateContext(ctx context.Context, host string, addrs ...netip.Addr) (*HostCreateResponse, error) {
+	x, err := encodeHostCreate(host, addrs)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.HostCreate, nil
+}
+
+// HostUpdateRequest represents an EPP request to update a host.
+// At least one of Add, Remove or NewName must be non-empty.
+// https://tools.ietf.org/html/rfc5732#section-3.2.5
+type HostUpdateRequest struct {
+	Host    string            // <host:name>
+	Add     HostUpdateSection // <host:add>
+	Remove  HostUpdateSection // <host:rem>
+	NewName string            // <host:chg><host:name>
+}
+
+// HostUpdateSection represents the attributes added to or removed from a host
+// in a <host:add> or <host:rem> element.
+type HostUpdateSection struct {
+	Addresses []netip.Addr // <host:addr>
+	Status    Status       // <host:status>; see ParseStatus
+}
+
+// IsZero reports whether s is empty.
+func (s *HostUpdateSection) IsZero() bool {
+	return len(s.Addresses) == 0 && s.Status == StatusUnknown
+}
+
+// HostUpdate updates a host.
+// https://tools.ietf.org/html/rfc5732#section-3.2.5
+func (c *Conn) HostUpdate(req *HostUpdateRequest) (Result, error) {
+	return c.HostUpdateContext(context.Background(), req)
+}
+
+// HostUpdateContext is like HostUpdate, but aborts if ctx is done before the server responds.
+func (c *Conn) HostUpdateContext(ctx context.Context, req *HostUpdateRequest) (Result, error) {
+	x, err := encodeHostUpdate(req)
+	if err != nil {
+		return Result{}, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return Result{}, err
+	}
+	return res.Result, nil
+}
+
+// HostDelete deletes a host. It returns pending == true if the server
+// accepted the request but the deletion is pending (result code 1001).
+// https://tools.ietf.org/html/rfc5732#section-3.2.2
+func (c *Conn) HostDelete(host string) (pending bool, err error) {
+	return c.HostDeleteContext(context.Background(), host)
+}
+
+// HostDeleteContext is like HostDelete, but aborts if ctx is done before the server responds.
+func (c *Conn) HostDeleteContext(ctx context.Context, host string) (pending bool, err error) {
+	x, err := encodeHostCommand("delete", host)
+	if err != nil {
+		return false, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	return res.Result.Code == ResultSuccessPending, nil
+}
+
+func encodeHostCheck(hosts []string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<check><host:check xmlns:host="urn:ietf:params:xml:ns:host-1.0">`)
+	for _, host := range hosts {
+		err := encodeHostName(buf, host)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString(`</host:check></check>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// encodeHostCommand encodes a host <info> or <delete> command, which
+// take a single <host:name> element.
+func encodeHostCommand(cmd, host string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<` + cmd + `><host:` + cmd + ` xmlns:host="urn:ietf:params:xml:ns:host-1.0">`)
+	err := encodeHostName(buf, host)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`</host:` + cmd + `></` + cmd + `>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeHostCreate(host string, addrs []netip.Addr) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<create><host:create xmlns:host="urn:ietf:params:xml:ns:host-1.0">`)
+	err := encodeHostName(buf, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		err = encodeHostAddr(buf, "host:addr", addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString(`</host:create></create>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeHostUpdate(req *HostUpdateRequest) ([]byte, error) {
+	if req.Add.IsZero() && req.Remove.IsZero() && req.NewName == "" {
+		return nil, errEmptyHostUpdate
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<update><host:update xmlns:host="urn:ietf:params:xml:ns:host-1.0">`)
+	err := encodeHostName(buf, req.Host)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Add.IsZero() {
+		buf.WriteString(`<host:add>`)
+		err = encodeHostUpdateSection(buf, &req.Add)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`</host:add>`)
+	}
+	if !req.Remove.IsZero() {
+		buf.WriteString(`<host:rem>`)
+		err = encodeHostUpdateSection(buf, &req.Remove)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`</host:rem>`)
+	}
+	if req.NewName != "" {
+		buf.WriteString(`<host:chg>`)
+		encodeHostName(buf, req.NewName)
+		buf.WriteString(`</host:chg>`)
+	}
+	buf.WriteString(`</host:update></update>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeHostUpdateSection(buf *bytes.Buffer, s *HostUpdateSection) error {
+	for _, addr := range s.Addresses {
+		err := encodeHostAddr(buf, "host:addr", addr)
+		if err != nil {
+			return err
+		}
+	}
+	for _, status := range s.Status.Strings() {
+		buf.WriteString(`<host:status s="`)
+		buf.WriteString(status)
+		buf.WriteString(`"/>`)
+	}
+	return nil
+}
+
+// encodeHostName writes a <host:name> element for host to buf.
+func encodeHostName(buf *bytes.Buffer, host string) error {
+	if host == "" {
+		return errMissingHost
+	}
+	buf.WriteString(`<host:name>`)
+	xml.EscapeText(buf, []byte(host))
+	buf.WriteString(`</host:name>`)
+	return nil
+}
+
+// HostCheckResponse represents an EPP <response> for a host check.
+// https://tools.ietf.org/html/rfc5732#section-3.1.1
+type HostCheckResponse struct {
+	Checks []HostCheck
+}
+
+// HostCheck represents a single host in an EPP <host:chkData>.
+type HostCheck struct {
+	Host      string
+	Reason    string
+	Available bool
+}
+
+// HostInfoResponse represents an EPP response for a host info request.
+// https://tools.ietf.org/html/rfc5732#section-3.1.2
+type HostInfoResponse struct {
+	Host      string       // <host:name>
+	ROID      string       // <host:roid>
+	Status    []string     // <host:status>
+	Addresses []netip.Addr // <host:addr>
+	ClID      string       // <host:clID>
+	CrID      string       // <host:crID>
+	UpID      string       // <host:upID>
+	CrDate    time.Time    // <host:crDate>
+	UpDate    time.Time    // <host:upDate>
+	TrDate    time.Time    // <host:trDate>
+}
+
+// HostCreateResponse represents an EPP response for a host create request.
+// https://tools.ietf.org/html/rfc5732#section-3.2.1
+type HostCreateResponse struct {
+	Host   string    // <host:name>
+	CrDate time.Time // <host:crDate>
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjHost + " chkData"
+	scanResponse.MustHandleStartElement(path+">cd", func(c *xx.Context) error {
+		hcr := &c.Value.(*Response).HostCheck
+		hcr.Checks = append(hcr.Checks, HostCheck{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>name", func(c *xx.Context) error {
+		checks := c.Value.(*Response).HostCheck.Checks
+		check := &checks[len(checks)-1]
+		check.Host = string(c.CharData)
+		check.Available = c.AttrBool("", "avail")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>reason", func(c *xx.Context) error {
+		checks := c.Value.(*Response).HostCheck.Checks
+		check := &checks[len(checks)-1]
+		check.Reason = string(c.CharData)
+		return nil
+	})
+
+	path = "epp > response > resData > " + ObjHost + " infData"
+	scanResponse.MustHandleCharData(path+">name", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		hir.Host = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">roid", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		hir.ROID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">status", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		hir.Status = append(hir.Status, c.Attr("", "s"))
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">addr", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		addr, err := netip.ParseAddr(string(c.CharData))
+		if err != nil {
+			return err
+		}
+		hir.Addresses = append(hir.Addresses, addr)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">clID", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		hir.ClID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crID", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		hir.CrID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">upID", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		hir.UpID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		var err error
+		hir.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">upDate", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		var err error
+		hir.UpDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">trDate", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfo
+		var err error
+		hir.TrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+
+	path = "epp > response > resData > " + ObjHost + " creData"
+	scanResponse.MustHandleCharData(path+">name", func(c *xx.Context) error {
+		hcr := &c.Value.(*Response).HostCreate
+		hcr.Host = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
+		hcr := &c.Value.(*Response).HostCreate
+		var err error
+		hcr.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+}
@@ -0,0 +1,202 @@
+package epp
+
+import (
+	"encoding/xml"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeHostCheck(t *testing.T) {
+	x, err := encodeHostCheck([]string{"ns1.example.com", "ns2.example.com"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><host:check xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name><host:name>ns2.example.com</host:name></host:check></check></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeHostInfoAndDelete(t *testing.T) {
+	x, err := encodeHostCommand("info", "ns1.example.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><host:info xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name></host:info></info></command></epp>`)
+
+	x, err = encodeHostCommand("delete", "ns1.example.com")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><delete><host:delete xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name></host:delete></delete></command></epp>`)
+
+	_, err = encodeHostCommand("info", "")
+	st.Expect(t, err, errMissingHost)
+}
+
+func TestEncodeHostCreate(t *testing.T) {
+	addrs := []netip.Addr{
+		netip.MustParseAddr("192.0.2.2"),
+		netip.MustParseAddr("::ffff:192.0.2.29"),
+		netip.MustParseAddr("1080:0:0:0:8:800:200C:417A"),
+	}
+	x, err := encodeHostCreate("ns1.example.com", addrs)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><host:create xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name><host:addr ip="v4">192.0.2.2</host:addr><host:addr ip="v4">192.0.2.29</host:addr><host:addr ip="v6">1080::8:800:200c:417a</host:addr></host:create></create></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeHostCreate("ns1.example.com", []netip.Addr{{}})
+	st.Expect(t, err, errInvalidAddress)
+}
+
+func TestEncodeHostUpdate(t *testing.T) {
+	req := &HostUpdateRequest{
+		Host: "ns1.example.com",
+		Add: HostUpdateSection{
+			Addresses: []netip.Addr{netip.MustParseAddr("192.0.2.22")},
+			Status:    StatusClientUpdateProhibited,
+		},
+		Remove: HostUpdateSection{
+			Addresses: []netip.Addr{netip.MustParseAddr("1080::8:800:200c:417a")},
+		},
+		NewName: "ns2.example.com",
+	}
+	x, err := encodeHostUpdate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><host:update xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name><host:add><host:addr ip="v4">192.0.2.22</host:addr><host:status s="clientUpdateProhibited"/></host:add><host:rem><host:addr ip="v6">1080::8:800:200c:417a</host:addr></host:rem><host:chg><host:name>ns2.example.com</host:name></host:chg></host:update></update></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeHostUpdate(&HostUpdateRequest{Host: "ns1.example.com"})
+	st.Expect(t, err, errEmptyHostUpdate)
+
+	_, err = encodeHostUpdate(&HostUpdateRequest{
+		Host:   "ns1.example.com",
+		Remove: HostUpdateSection{Addresses: []netip.Addr{{}}},
+	})
+	st.Expect(t, err, errInvalidAddress)
+}
+
+func TestScanHostCheckResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<host:chkData xmlns:host="urn:ietf:params:xml:ns:host-1.0">
+				<host:cd>
+					<host:name avail="1">ns1.example.com</host:name>
+				</host:cd>
+				<host:cd>
+					<host:name avail="0">ns2.example2.com</host:name>
+					<host:reason>In use</host:reason>
+				</host:cd>
+			</host:chkData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	hcr := &res.HostCheck
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, hcr.Checks, []HostCheck{
+		{Host: "ns1.example.com", Available: true},
+		{Host: "ns2.example2.com", Reason: "In use"},
+	})
+	st.Expect(t, len(res.DomainCheckResponse.Checks), 0)
+}
+
+func TestScanHostInfoResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<host:infData xmlns:host="urn:ietf:params:xml:ns:host-1.0">
+				<host:name>ns1.example.com</host:name>
+				<host:roid>NS1_EXAMPLE1-REP</host:roid>
+				<host:status s="linked"/>
+				<host:status s="clientUpdateProhibited"/>
+				<host:addr ip="v4">192.0.2.2</host:addr>
+				<host:addr ip="v4">192.0.2.29</host:addr>
+				<host:addr ip="v6">1080:0:0:0:8:800:200C:417A</host:addr>
+				<host:clID>ClientY</host:clID>
+				<host:crID>ClientX</host:crID>
+				<host:crDate>1999-04-03T22:00:00.0Z</host:crDate>
+				<host:upID>ClientX</host:upID>
+				<host:upDate>1999-12-03T09:00:00.0Z</host:upDate>
+				<host:trDate>2000-04-08T09:00:00.0Z</host:trDate>
+			</host:infData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	hir := &res.HostInfo
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, hir.Host, "ns1.example.com")
+	st.Expect(t, hir.ROID, "NS1_EXAMPLE1-REP")
+	st.Expect(t, hir.Status, []string{"linked", "clientUpdateProhibited"})
+	st.Expect(t, hir.Addresses, []netip.Addr{
+		netip.MustParseAddr("192.0.2.2"),
+		netip.MustParseAddr("192.0.2.29"),
+		netip.MustParseAddr("1080::8:800:200c:417a"),
+	})
+	st.Expect(t, hir.ClID, "ClientY")
+	st.Expect(t, hir.CrID, "ClientX")
+	st.Expect(t, hir.UpID, "ClientX")
+	st.Expect(t, hir.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, hir.UpDate, time.Date(1999, 12, 3, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, hir.TrDate, time.Date(2000, 4, 8, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, res.DomainInfoResponse.Domain, "")
+}
+
+func TestScanHostCreateResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<host:creData xmlns:host="urn:ietf:params:xml:ns:host-1.0">
+				<host:name>ns1.example.com</host:name>
+				<host:crDate>1999-04-03T22:00:00.0Z</host:crDate>
+			</host:creData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	hcr := &res.HostCreate
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, hcr.Host, "ns1.example.com")
+	st.Expect(t, hcr.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+}
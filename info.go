@@ -2,7 +2,9 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"net/netip"
 	"time"
 
 	"github.com/nbio/xx"
@@ -11,26 +13,84 @@ import (
 // DomainInfo retrieves info for a domain.
 // https://tools.ietf.org/html/rfc5731#section-3.1.2
 func (c *Conn) DomainInfo(domain string, extData map[string]string) (*DomainInfoResponse, error) {
-	x, err := encodeDomainInfo(&c.Greeting, domain, extData)
+	return c.DomainInfoWithOptionsContext(context.Background(), domain, &DomainInfoOptions{ExtData: extData})
+}
+
+// DomainInfoContext is like DomainInfo, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainInfoContext(ctx context.Context, domain string, extData map[string]string) (*DomainInfoResponse, error) {
+	return c.DomainInfoWithOptionsContext(ctx, domain, &DomainInfoOptions{ExtData: extData})
+}
+
+// Values for DomainInfoOptions.Hosts, selecting which host information is returned.
+// https://tools.ietf.org/html/rfc5731#section-3.1.2
+const (
+	HostsAll         = "all"  // delegated and subordinate hosts
+	HostsDelegated   = "del"  // delegated hosts only
+	HostsSubordinate = "sub"  // subordinate hosts only
+	HostsNone        = "none" // no host information
+)
+
+// DomainInfoOptions specifies optional parameters for a domain info request.
+type DomainInfoOptions struct {
+	// Hosts selects the host information to return. Defaults to HostsNone.
+	Hosts string
+
+	// AuthInfo is the domain authorization password, which allows
+	// a client to retrieve full info for a domain sponsored by another client.
+	AuthInfo string
+
+	// ExtData specifies extension data, as for DomainInfo.
+	ExtData map[string]string
+}
+
+// DomainInfoWithOptions retrieves info for a domain, with the options specified in opts.
+// https://tools.ietf.org/html/rfc5731#section-3.1.2
+func (c *Conn) DomainInfoWithOptions(domain string, opts *DomainInfoOptions) (*DomainInfoResponse, error) {
+	return c.DomainInfoWithOptionsContext(context.Background(), domain, opts)
+}
+
+// DomainInfoWithOptionsContext is like DomainInfoWithOptions, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainInfoWithOptionsContext(ctx context.Context, domain string, opts *DomainInfoOptions) (*DomainInfoResponse, error) {
+	g := c.LastGreeting()
+	x, err := encodeDomainInfo(&g, domain, opts)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
+	tx, err := c.writeRequest(ctx, x)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.readResponse()
+	res, err := c.readResponse(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 	return &res.DomainInfoResponse, nil
 }
 
-func encodeDomainInfo(greeting *Greeting, domain string, extData map[string]string) ([]byte, error) {
+func encodeDomainInfo(greeting *Greeting, domain string, opts *DomainInfoOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &DomainInfoOptions{}
+	}
+	hosts := opts.Hosts
+	switch hosts {
+	case "":
+		hosts = HostsNone
+	case HostsAll, HostsDelegated, HostsSubordinate, HostsNone:
+	default:
+		return nil, errInvalidHosts
+	}
+	extData := opts.ExtData
+
 	buf := bytes.NewBufferString(xmlCommandPrefix)
-	buf.WriteString(`<info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="none">`)
+	buf.WriteString(`<info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="`)
+	buf.WriteString(hosts)
+	buf.WriteString(`">`)
 	xml.EscapeText(buf, []byte(domain))
-	buf.WriteString(`</domain:name></domain:info></info>`)
+	buf.WriteString(`</domain:name>`)
+	if opts.AuthInfo != "" {
+		encodeDomainAuthInfo(buf, opts.AuthInfo)
+	}
+	buf.WriteString(`</domain:info></info>`)
 
 	supportsNamestore := extData["namestoreExt:subProduct"] != "" && greeting.SupportsExtension(ExtNamestore)
 	hasExtension := supportsNamestore
@@ -58,15 +118,26 @@ func encodeDomainInfo(greeting *Greeting, domain string, extData map[string]stri
 // DomainInfoResponse represents an EPP response for a domain info request.
 // https://tools.ietf.org/html/rfc5731#section-3.1.2
 type DomainInfoResponse struct {
-	Domain string    // <domain:name>
-	ID     string    // <domain:roid>
-	ClID   string    // <domain:clID>
-	UpID   string    // <domain:upID>
-	CrDate time.Time // <domain:crDate>
-	ExDate time.Time // <domain:exDate>
-	UpDate time.Time // <domain:upDate>
-	TrDate time.Time // <domain:trDate>
-	Status []string  // <domain:status>
+	Domain      string          // <domain:name>
+	ROID        string          // <domain:roid>
+	ClID        string          // <domain:clID>
+	CrID        string          // <domain:crID>
+	UpID        string          // <domain:upID>
+	CrDate      time.Time       // <domain:crDate>
+	ExDate      time.Time       // <domain:exDate>
+	UpDate      time.Time       // <domain:upDate>
+	TrDate      time.Time       // <domain:trDate>
+	Status      []string        // <domain:status>
+	Registrant  string          // <domain:registrant>
+	Contacts    []DomainContact // <domain:contact>
+	Nameservers []Nameserver    // <domain:ns>
+	Hosts       []string        // <domain:host>, subordinate hosts
+	AuthInfo    string          // <domain:authInfo><domain:pw>
+
+	// ID is the <domain:roid>.
+	//
+	// Deprecated: Use ROID, as for contacts and hosts.
+	ID string
 }
 
 func init() {
@@ -79,7 +150,8 @@ func init() {
 	})
 	scanResponse.MustHandleCharData(path+">roid", func(c *xx.Context) error {
 		dir := &c.Value.(*Response).DomainInfoResponse
-		dir.ID = string(c.CharData)
+		dir.ROID = string(c.CharData)
+		dir.ID = dir.ROID
 		return nil
 	})
 	scanResponse.MustHandleCharData(path+">clID", func(c *xx.Context) error {
@@ -121,6 +193,60 @@ func init() {
 		dir.Status = append(dir.Status, c.Attr("", "s"))
 		return nil
 	})
+	scanResponse.MustHandleCharData(path+">crID", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.CrID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">registrant", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Registrant = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">contact", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Contacts = append(dir.Contacts, DomainContact{
+			Type: ContactType(c.Attr("", "type")),
+			ID:   string(c.CharData),
+		})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">ns>hostObj", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Nameservers = append(dir.Nameservers, Nameserver{Host: string(c.CharData)})
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">ns>hostAttr", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Nameservers = append(dir.Nameservers, Nameserver{Attr: true})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">ns>hostAttr>hostName", func(c *xx.Context) error {
+		nameservers := c.Value.(*Response).DomainInfoResponse.Nameservers
+		ns := &nameservers[len(nameservers)-1]
+		ns.Host = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">ns>hostAttr>hostAddr", func(c *xx.Context) error {
+		nameservers := c.Value.(*Response).DomainInfoResponse.Nameservers
+		ns := &nameservers[len(nameservers)-1]
+		addr, err := netip.ParseAddr(string(c.CharData))
+		if err != nil {
+			return err
+		}
+		ns.Addresses = append(ns.Addresses, addr)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">host", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Hosts = append(dir.Hosts, string(c.CharData))
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">authInfo>pw", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.AuthInfo = string(c.CharData)
+		return nil
+	})
 }
 
 //lint:ignore U1000 keeping around for reference
@@ -141,24 +267,6 @@ func encodeVerisignDomainInfo(buf *bytes.Buffer, domain string) error {
 	return nil
 }
 
-//lint:ignore U1000 keeping around for reference
-func encodeVerisignContactInfo(buf *bytes.Buffer, contact string) error {
-	buf.Reset()
-	buf.WriteString(xmlCommandPrefix)
-	buf.WriteString(`<info><contact:info xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>`)
-	xml.EscapeText(buf, []byte(contact))
-	buf.WriteString(`</contact:id></contact:info></info>`)
-	buf.WriteString(`<extension>`)
-	buf.WriteString(`<namestoreExt:namestoreExt xmlns:namestoreExt="http://www.verisign-grs.com/epp/namestoreExt-1.1">`)
-	buf.WriteString(`<namestoreExt:subProduct>`)
-	buf.WriteString(`com`)
-	buf.WriteString(`</namestoreExt:subProduct>`)
-	buf.WriteString(`</namestoreExt:namestoreExt>`)
-	buf.WriteString(`</extension>`)
-	buf.WriteString(xmlCommandSuffix)
-	return nil
-}
-
 /*
 <?xml version="1.0" encoding="UTF-8" standalone="no"?>
 <epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
@@ -177,21 +285,4 @@ func encodeVerisignContactInfo(buf *bytes.Buffer, contact string) error {
     <clTRID>ABC-12345</clTRID>
   </command>
 </epp>
-
-
-<?xml version="1.0" encoding="UTF-8" standalone="no"?>
-<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
-  <command>
-    <info>
-      <contact:info
-       xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
-        <contact:id>sh8013</contact:id>
-        <contact:authInfo>
-          <contact:pw>2fooBAR</contact:pw>
-        </contact:authInfo>
-      </contact:info>
-    </info>
-    <clTRID>ABC-12345</clTRID>
-  </command>
-</epp>
 */
@@ -0,0 +1,148 @@
+package epp
+
+import (
+	"encoding/xml"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainInfo(t *testing.T) {
+	x, err := encodeDomainInfo(nil, "example.com", nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="none">example.com</domain:name></domain:info></info></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeDomainInfoOptions(t *testing.T) {
+	x, err := encodeDomainInfo(nil, "example.com", &DomainInfoOptions{Hosts: HostsAll, AuthInfo: "2fooBAR"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="all">example.com</domain:name><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:info></info></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodeDomainInfo(nil, "example.com", &DomainInfoOptions{Hosts: "some"})
+	st.Expect(t, err, errInvalidHosts)
+}
+
+func TestScanDomainInfoResponse(t *testing.T) {
+	var res Response
+	dir := &res.DomainInfoResponse
+
+	d := decoder(testXMLDomainInfoResponse)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, dir.Domain, "example.com")
+	st.Expect(t, dir.ROID, "EXAMPLE1-REP")
+	st.Expect(t, dir.ID, "EXAMPLE1-REP")
+	st.Expect(t, dir.Status, []string{"ok"})
+	st.Expect(t, dir.Registrant, "jd1234")
+	st.Expect(t, dir.Contacts, []DomainContact{
+		{Type: ContactAdmin, ID: "sh8013"},
+		{Type: ContactTech, ID: "sh8013"},
+	})
+	st.Expect(t, dir.Nameservers, []Nameserver{
+		{Host: "ns1.example.com"},
+		{Host: "ns1.example.net"},
+	})
+	st.Expect(t, dir.Hosts, []string{"ns1.example.com", "ns2.example.com"})
+	st.Expect(t, dir.ClID, "ClientX")
+	st.Expect(t, dir.CrID, "ClientY")
+	st.Expect(t, dir.UpID, "ClientX")
+	st.Expect(t, dir.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dir.UpDate, time.Date(1999, 12, 3, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, dir.ExDate, time.Date(2000, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dir.TrDate, time.Date(2000, 4, 8, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, dir.AuthInfo, "2fooBAR")
+}
+
+func TestScanDomainInfoResponseHostAttr(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>example.com</domain:name>
+				<domain:roid>EXAMPLE1-REP</domain:roid>
+				<domain:ns>
+					<domain:hostAttr>
+						<domain:hostName>ns1.example.com</domain:hostName>
+						<domain:hostAddr ip="v4">192.0.2.2</domain:hostAddr>
+						<domain:hostAddr ip="v6">1080:0:0:0:8:800:200C:417A</domain:hostAddr>
+					</domain:hostAttr>
+					<domain:hostAttr>
+						<domain:hostName>ns2.example.net</domain:hostName>
+					</domain:hostAttr>
+				</domain:ns>
+				<domain:clID>ClientX</domain:clID>
+			</domain:infData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	dir := &res.DomainInfoResponse
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, dir.Nameservers, []Nameserver{
+		{
+			Host:      "ns1.example.com",
+			Addresses: []netip.Addr{netip.MustParseAddr("192.0.2.2"), netip.MustParseAddr("1080::8:800:200c:417a")},
+			Attr:      true,
+		},
+		{Host: "ns2.example.net", Attr: true},
+	})
+}
+
+var testXMLDomainInfoResponse = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>example.com</domain:name>
+				<domain:roid>EXAMPLE1-REP</domain:roid>
+				<domain:status s="ok"/>
+				<domain:registrant>jd1234</domain:registrant>
+				<domain:contact type="admin">sh8013</domain:contact>
+				<domain:contact type="tech">sh8013</domain:contact>
+				<domain:ns>
+					<domain:hostObj>ns1.example.com</domain:hostObj>
+					<domain:hostObj>ns1.example.net</domain:hostObj>
+				</domain:ns>
+				<domain:host>ns1.example.com</domain:host>
+				<domain:host>ns2.example.com</domain:host>
+				<domain:clID>ClientX</domain:clID>
+				<domain:crID>ClientY</domain:crID>
+				<domain:crDate>1999-04-03T22:00:00.0Z</domain:crDate>
+				<domain:upID>ClientX</domain:upID>
+				<domain:upDate>1999-12-03T09:00:00.0Z</domain:upDate>
+				<domain:exDate>2000-04-03T22:00:00.0Z</domain:exDate>
+				<domain:trDate>2000-04-08T09:00:00.0Z</domain:trDate>
+				<domain:authInfo>
+					<domain:pw>2fooBAR</domain:pw>
+				</domain:authInfo>
+			</domain:infData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
@@ -0,0 +1,159 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"runtime"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+// loginSecPassword replaces <pw> and <newPW> in a <login> command
+// when the passwords are sent in the login security extension.
+// https://tools.ietf.org/html/rfc8807#section-4.1
+const loginSecPassword = "[LOGIN-SECURITY]"
+
+// UserAgent identifies the client software to the server
+// in a login security extension <loginSec:userAgent> element.
+// https://tools.ietf.org/html/rfc8807#section-3.2
+type UserAgent struct {
+	App  string // client application name and version
+	Tech string // technology or language, e.g. "go1.22.1"
+	OS   string // client operating system
+}
+
+// IsZero returns true if ua has no fields set.
+func (ua *UserAgent) IsZero() bool {
+	return ua.App == "" && ua.Tech == "" && ua.OS == ""
+}
+
+// DefaultUserAgent is the UserAgent sent with a login security
+// extension when LoginOptions.UserAgent is nil.
+var DefaultUserAgent = UserAgent{
+	App:  "github.com/domainr/epp",
+	Tech: runtime.Version(),
+	OS:   runtime.GOOS + "/" + runtime.GOARCH,
+}
+
+// Login security event types.
+// https://tools.ietf.org/html/rfc8807#section-3.1
+const (
+	SecurityEventPassword    = "password"
+	SecurityEventCertificate = "certificate"
+	SecurityEventCipher      = "cipher"
+	SecurityEventTLSProtocol = "tlsProtocol"
+	SecurityEventNewPassword = "newPW"
+	SecurityEventStat        = "stat"
+	SecurityEventCustom      = "custom"
+)
+
+// Login security event levels.
+const (
+	SecurityLevelWarning = "warning"
+	SecurityLevelError   = "error"
+)
+
+// SecurityEvent represents a <loginSec:event> element returned
+// in the <loginSec:loginSecData> extension of a login response.
+// https://tools.ietf.org/html/rfc8807#section-3.1
+type SecurityEvent struct {
+	Type     string    // type attribute, e.g. SecurityEventPassword
+	Name     string    // name of a stat or custom event
+	Level    string    // SecurityLevelWarning or SecurityLevelError
+	ExDate   time.Time // expiration date of a password or certificate, if any
+	Value    string    // value that triggered the event, e.g. a cipher name
+	Duration string    // XML duration of a stat event, e.g. "P1D"
+	Language string    // lang attribute of the event description
+	Message  string    // human-readable description
+}
+
+// IsError returns true if e is an error-level event.
+func (e *SecurityEvent) IsError() bool {
+	return e.Level == SecurityLevelError
+}
+
+// encodeLoginSec encodes a <login> command with the passwords
+// and user agent sent in a login security extension.
+func encodeLoginSec(user, password, newPassword string, ua *UserAgent, version, language string, objects, extensions []string) ([]byte, error) {
+	newPW := ""
+	if len(newPassword) > 0 {
+		newPW = loginSecPassword
+	}
+	x, err := encodeLogin(user, loginSecPassword, newPW, version, language, objects, extensions)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(x[:len(x)-len(xmlCommandSuffix)])
+	buf.WriteString(`<extension><loginSec:loginSec xmlns:loginSec="` + ExtLoginSec + `">`)
+	if !ua.IsZero() {
+		buf.WriteString(`<loginSec:userAgent>`)
+		if ua.App != "" {
+			buf.WriteString(`<loginSec:app>`)
+			xml.EscapeText(buf, []byte(ua.App))
+			buf.WriteString(`</loginSec:app>`)
+		}
+		if ua.Tech != "" {
+			buf.WriteString(`<loginSec:tech>`)
+			xml.EscapeText(buf, []byte(ua.Tech))
+			buf.WriteString(`</loginSec:tech>`)
+		}
+		if ua.OS != "" {
+			buf.WriteString(`<loginSec:os>`)
+			xml.EscapeText(buf, []byte(ua.OS))
+			buf.WriteString(`</loginSec:os>`)
+		}
+		buf.WriteString(`</loginSec:userAgent>`)
+	}
+	buf.WriteString(`<loginSec:pw>`)
+	xml.EscapeText(buf, []byte(password))
+	buf.WriteString(`</loginSec:pw>`)
+	if len(newPassword) > 0 {
+		buf.WriteString(`<loginSec:newPW>`)
+		xml.EscapeText(buf, []byte(newPassword))
+		buf.WriteString(`</loginSec:newPW>`)
+	}
+	buf.WriteString(`</loginSec:loginSec></extension>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// scanSecurityEvent calls f with the <loginSec:event> element currently
+// being scanned, and copies the events to each result in the response.
+func scanSecurityEvent(c *xx.Context, f func(e *SecurityEvent)) {
+	res := c.Value.(*Response)
+	if len(res.Result.SecurityEvents) == 0 {
+		return
+	}
+	f(&res.Result.SecurityEvents[len(res.Result.SecurityEvents)-1])
+	for i := range res.Results {
+		res.Results[i].SecurityEvents = res.Result.SecurityEvents
+	}
+}
+
+func init() {
+	path := "epp > response > extension > " + ExtLoginSec + " loginSecData > event"
+	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+		res := c.Value.(*Response)
+		res.Result.SecurityEvents = append(res.Result.SecurityEvents, SecurityEvent{})
+		var err error
+		scanSecurityEvent(c, func(e *SecurityEvent) {
+			e.Type = c.Attr("", "type")
+			e.Name = c.Attr("", "name")
+			e.Level = c.Attr("", "level")
+			e.Value = c.Attr("", "value")
+			e.Duration = c.Attr("", "duration")
+			e.Language = c.Attr("", "lang")
+			if exDate := c.Attr("", "exDate"); exDate != "" {
+				e.ExDate, err = time.Parse(time.RFC3339, exDate)
+			}
+		})
+		return err
+	})
+	scanResponse.MustHandleCharData(path, func(c *xx.Context) error {
+		scanSecurityEvent(c, func(e *SecurityEvent) {
+			e.Message = string(c.CharData)
+		})
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+package epp
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeLoginSec(t *testing.T) {
+	ua := &UserAgent{App: "EPP SDK 1.0.0", Tech: "go1.22", OS: "linux/amd64"}
+	x, err := encodeLoginSec("jane", "this is a long password", "a much longer new password", ua, "1.0", "en", nil, []string{ExtLoginSec})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><login><clID>jane</clID><pw>[LOGIN-SECURITY]</pw><newPW>[LOGIN-SECURITY]</newPW><options><version>1.0</version><lang>en</lang></options><svcs><svcExtension><extURI>urn:ietf:params:xml:ns:epp:loginSec-1.0</extURI></svcExtension></svcs></login><extension><loginSec:loginSec xmlns:loginSec="urn:ietf:params:xml:ns:epp:loginSec-1.0"><loginSec:userAgent><loginSec:app>EPP SDK 1.0.0</loginSec:app><loginSec:tech>go1.22</loginSec:tech><loginSec:os>linux/amd64</loginSec:os></loginSec:userAgent><loginSec:pw>this is a long password</loginSec:pw><loginSec:newPW>a much longer new password</loginSec:newPW></loginSec:loginSec></extension></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	x, err = encodeLoginSec("jane", "battery", "", &UserAgent{}, "1.0", "en", nil, []string{ExtLoginSec})
+	st.Expect(t, err, nil)
+	st.Expect(t, strings.Contains(string(x), "newPW"), false)
+	st.Expect(t, strings.Contains(string(x), "userAgent"), false)
+	st.Expect(t, testElement(string(x), "loginSec:pw"), "battery")
+}
+
+func TestScanLoginSecData(t *testing.T) {
+	res, err := scanDataUnit([]byte(testXMLLoginSecResponse))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Result.Code, ResultSuccess)
+	events := res.Result.SecurityEvents
+	st.Assert(t, len(events), 3)
+	st.Expect(t, events[0], SecurityEvent{
+		Type:     SecurityEventPassword,
+		Level:    SecurityLevelWarning,
+		ExDate:   time.Date(2020, 3, 25, 0, 0, 0, 0, time.UTC),
+		Language: "en",
+		Message:  "Password expiring in a week",
+	})
+	st.Expect(t, events[1].Type, SecurityEventCipher)
+	st.Expect(t, events[1].Value, "NULL-MD5")
+	st.Expect(t, events[1].Message, "Non-PFS Cipher negotiated")
+	st.Expect(t, events[2], SecurityEvent{
+		Type:     SecurityEventStat,
+		Name:     "failedLogins",
+		Level:    SecurityLevelWarning,
+		Value:    "100",
+		Duration: "P1D",
+		Message:  "Excessive invalid daily logins",
+	})
+	st.Expect(t, res.Results[0].SecurityEvents, events)
+}
+
+func TestLoginSec(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		g := strings.Replace(testXMLGreeting, "<extURI>", "<extURI>"+ExtLoginSec+"</extURI><extURI>", 1)
+		err = writeDataUnit(conn, []byte(g))
+		st.Assert(t, err, nil)
+		x, err := readTestRequest(conn)
+		st.Assert(t, err, nil)
+		st.Expect(t, testElement(x, "pw"), loginSecPassword)
+		st.Expect(t, testElement(x, "newPW"), loginSecPassword)
+		st.Expect(t, testElement(x, "loginSec:pw"), "battery")
+		st.Expect(t, testElement(x, "loginSec:newPW"), "correct horse battery staple")
+		st.Expect(t, testElement(x, "loginSec:app"), DefaultUserAgent.App)
+		err = writeDataUnit(conn, []byte(testXMLLoginSecErrorResponse))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	_, err = c.Login("jane", "battery", "correct horse battery staple")
+	var r *Result
+	st.Assert(t, errors.As(err, &r), true)
+	st.Expect(t, r.Code, ResultAuthenticationError)
+	st.Assert(t, len(r.SecurityEvents), 1)
+	st.Expect(t, r.SecurityEvents[0].Type, SecurityEventNewPassword)
+	st.Expect(t, r.SecurityEvents[0].IsError(), true)
+}
+
+var testXMLLoginSecResponse = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<extension>
+			<loginSec:loginSecData xmlns:loginSec="urn:ietf:params:xml:ns:epp:loginSec-1.0">
+				<loginSec:event type="password" level="warning" exDate="2020-03-25T00:00:00Z" lang="en">Password expiring in a week</loginSec:event>
+				<loginSec:event type="cipher" level="warning" value="NULL-MD5">Non-PFS Cipher negotiated</loginSec:event>
+				<loginSec:event type="stat" name="failedLogins" level="warning" value="100" duration="P1D">Excessive invalid daily logins</loginSec:event>
+			</loginSec:loginSecData>
+		</extension>
+		<trID>
+			<clTRID>ABC-12345</clTRID>
+			<svTRID>54321-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+var testXMLLoginSecErrorResponse = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="2200">
+			<msg>Authentication error</msg>
+		</result>
+		<extension>
+			<loginSec:loginSecData xmlns:loginSec="urn:ietf:params:xml:ns:epp:loginSec-1.0">
+				<loginSec:event type="newPW" level="error">New password does not meet complexity requirements</loginSec:event>
+			</loginSec:loginSecData>
+		</extension>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
@@ -0,0 +1,346 @@
+package epp
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"slices"
+	"sync"
+)
+
+var (
+	errPasswordLength   = errors.New("epp: password must be at least 6 characters")
+	errPasswordTooLong  = errors.New("epp: password longer than 16 characters requires login security extension")
+	errMissingStore     = errors.New("epp: missing credential store")
+	errPasswordUnstored = errors.New("epp: new password not stored")
+)
+
+// DefaultPasswordLength is the length of passwords generated by
+// PasswordRotator, the maximum allowed by EPP without the login
+// security extension (RFC 8807).
+// https://tools.ietf.org/html/rfc5730#section-4
+const DefaultPasswordLength = 16
+
+// maxPasswordLength is the maximum length of an EPP <pw> or <newPW>.
+const maxPasswordLength = 16
+
+// Character classes used by GeneratePassword. The symbols exclude
+// characters that need escaping in XML or are easily confused.
+const (
+	passwordLower   = "abcdefghijkmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordDigits  = "23456789"
+	passwordSymbols = "!#$%*+-=?@^_"
+)
+
+// CredentialStore loads and saves the credentials used by a PasswordRotator.
+// A new password is saved in two steps: as the pending password before it is
+// sent to the server, then as the current password once the server accepts
+// it, so it is not lost if the process stops in between.
+type CredentialStore interface {
+	// Credentials returns the user, the current password, and the pending
+	// password saved by SetPendingPassword, if any.
+	Credentials(ctx context.Context) (user, password, pending string, err error)
+
+	// SetPendingPassword persists password as the pending password, keeping
+	// the current password. It must be durable when it returns.
+	SetPendingPassword(ctx context.Context, password string) error
+
+	// SetPassword persists password as the current password and clears the
+	// pending password. It must replace both atomically, so a failure leaves
+	// the store unchanged, and it must be durable when it returns.
+	SetPassword(ctx context.Context, password string) error
+}
+
+// PasswordChangeError is returned by PasswordRotator.Open when the server
+// may have accepted a new password that is not saved as the current password
+// in the CredentialStore: either the CredentialStore failed to save it, or the
+// <login> changing it failed without a response from the server (e.g. the
+// connection was lost). Unless saving it as the pending password failed, the
+// next call to Open tries it. Password may be the only valid password for
+// the account.
+type PasswordChangeError struct {
+	Password string
+	Err      error
+}
+
+// Error implements the error interface. It does not include the password.
+func (e *PasswordChangeError) Error() string {
+	return errPasswordUnstored.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error from the CredentialStore or the <login>.
+func (e *PasswordChangeError) Unwrap() error {
+	return e.Err
+}
+
+// PasswordRotator logs in EPP sessions with credentials from a
+// CredentialStore, changing the password when the server reports it
+// has expired or will expire soon. To use it with a Pool or Client,
+// set their Open field to the Open method of a PasswordRotator.
+type PasswordRotator struct {
+	// Dial opens a new EPP connection, with the server greeting read.
+	Dial func(ctx context.Context) (*Conn, error)
+
+	// Store holds the user and password.
+	Store CredentialStore
+
+	// LoginOptions, if set, are used to log in each session.
+	LoginOptions *LoginOptions
+
+	// Limiter, if set, limits the rate of commands sent in each session.
+	Limiter RateLimiter
+
+	// NewPassword returns a new password that meets the server's policy.
+	// Defaults to GeneratePassword(DefaultPasswordLength).
+	NewPassword func() (string, error)
+
+	// m serializes password changes.
+	m sync.Mutex
+}
+
+// Open dials and logs in a new session with the stored credentials.
+// If the login fails because the password has expired, or succeeds with
+// an RFC 8807 password expiry warning, Open logs in again, changing the
+// password with <newPW>, and saves the new password in the Store before
+// returning the logged-in session. Only one password change is made at a
+// time: concurrent calls wait for it, then log in with the new password.
+// If the Store has a pending password, left by a change that may not have
+// completed, Open first tries to log in with it.
+func (r *PasswordRotator) Open(ctx context.Context) (*Conn, error) {
+	if r.Store == nil {
+		return nil, errMissingStore
+	}
+	user, password, pending, err := r.Store.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pending != "" {
+		return r.rotate(ctx, nil, password)
+	}
+	c, ok, err := r.login(ctx, nil, user, password)
+	if err != nil || ok {
+		return c, err
+	}
+	return r.rotate(ctx, c, password)
+}
+
+// login logs in c, or a new connection if c is nil, with password.
+// It returns the session and true if the password need not be changed.
+// Otherwise it returns a connection to change the password, not logged in.
+func (r *PasswordRotator) login(ctx context.Context, c *Conn, user, password string) (*Conn, bool, error) {
+	var err error
+	if c == nil {
+		c, err = r.dial(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	res, err := c.LoginWithOptionsContext(ctx, user, password, "", r.LoginOptions)
+	switch {
+	case err == nil && !passwordExpiring(&res):
+		return c, true, nil
+	case err == nil:
+		// The password can only be changed by <login>, so log in a new session.
+		c.Close()
+	case !IsPasswordExpired(err):
+		c.Conn.Close()
+		return nil, false, err
+	case sessionLost(c, err):
+		c.Conn.Close()
+	default:
+		// Retry the login on the same connection.
+		return c, false, nil
+	}
+	c, err = r.dial(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, false, nil
+}
+
+// rotate changes the password, using c, or a new connection if c is nil.
+// Since another session may have changed the password after old was read,
+// it reads the Store again once no other change is in progress, and logs
+// in with the pending or current password if either is new.
+func (r *PasswordRotator) rotate(ctx context.Context, c *Conn, old string) (*Conn, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	user, password, pending, err := r.Store.Credentials(ctx)
+	if err != nil {
+		if c != nil {
+			c.Conn.Close()
+		}
+		return nil, err
+	}
+	if pending != "" {
+		var ok bool
+		c, ok, err = r.loginPending(ctx, c, user, pending)
+		if err != nil || ok {
+			return c, err
+		}
+	}
+	if pending != "" || password != old {
+		var ok bool
+		c, ok, err = r.login(ctx, c, user, password)
+		if err != nil {
+			return nil, err
+		}
+		if ok && pending != "" {
+			// The server did not accept the pending password.
+			err = r.Store.SetPassword(ctx, password)
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+		if ok {
+			return c, nil
+		}
+	}
+	return r.changePassword(ctx, c, user, password)
+}
+
+// loginPending logs in c, or a new connection if c is nil, with the pending
+// password, saving it as the current password if the server accepts it.
+// It returns the session and true if the login succeeded. Otherwise it
+// returns a connection that is not logged in, or nil.
+func (r *PasswordRotator) loginPending(ctx context.Context, c *Conn, user, pending string) (*Conn, bool, error) {
+	var err error
+	if c == nil {
+		c, err = r.dial(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	_, err = c.LoginWithOptionsContext(ctx, user, pending, "", r.LoginOptions)
+	switch {
+	case err == nil:
+		err = r.Store.SetPassword(ctx, pending)
+		if err != nil {
+			c.Close()
+			return nil, false, &PasswordChangeError{Password: pending, Err: err}
+		}
+		return c, true, nil
+	case !IsAuthError(err):
+		c.Conn.Close()
+		return nil, false, err
+	case sessionLost(c, err):
+		c.Conn.Close()
+		return nil, false, nil
+	}
+	return c, false, nil
+}
+
+// changePassword logs in c with a new password, and saves it in r.Store.
+// The new password is saved as pending before it is sent to the server.
+func (r *PasswordRotator) changePassword(ctx context.Context, c *Conn, user, password string) (*Conn, error) {
+	newPassword, err := r.newPassword()
+	if err == nil && len(newPassword) > maxPasswordLength && !c.usesLoginSec(r.LoginOptions) {
+		err = errPasswordTooLong
+	}
+	if err == nil {
+		err = r.Store.SetPendingPassword(ctx, newPassword)
+	}
+	if err != nil {
+		c.Conn.Close()
+		return nil, err
+	}
+	_, err = c.LoginWithOptionsContext(ctx, user, password, newPassword, r.LoginOptions)
+	var res *Result
+	if errors.As(err, &res) {
+		// The server rejected the new password, so clear it.
+		c.Conn.Close()
+		r.Store.SetPassword(ctx, password)
+		return nil, err
+	}
+	if err != nil {
+		// The server may have changed the password before the error.
+		c.Conn.Close()
+		return nil, &PasswordChangeError{Password: newPassword, Err: err}
+	}
+	err = r.Store.SetPassword(ctx, newPassword)
+	if err != nil {
+		c.Close()
+		return nil, &PasswordChangeError{Password: newPassword, Err: err}
+	}
+	return c, nil
+}
+
+func (r *PasswordRotator) dial(ctx context.Context) (*Conn, error) {
+	c, err := r.Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.Limiter = r.Limiter
+	return c, nil
+}
+
+func (r *PasswordRotator) newPassword() (string, error) {
+	if r.NewPassword != nil {
+		return r.NewPassword()
+	}
+	return GeneratePassword(DefaultPasswordLength)
+}
+
+// usesLoginSec reports whether a login with opts on c
+// will send passwords in the login security extension.
+func (c *Conn) usesLoginSec(opts *LoginOptions) bool {
+	g := c.LastGreeting()
+	o, err := g.loginOptions(opts)
+	return err == nil && slices.Contains(o.Extensions, ExtLoginSec)
+}
+
+// passwordExpiring reports whether r contains an RFC 8807
+// password event, warning that the password will expire soon.
+func passwordExpiring(r *Result) bool {
+	for _, e := range r.SecurityEvents {
+		if e.Type == SecurityEventPassword {
+			return true
+		}
+	}
+	return false
+}
+
+// GeneratePassword returns a random password of length n, with at least
+// one lowercase letter, uppercase letter, digit and symbol, suitable for
+// an EPP <newPW>. Passwords longer than 16 characters require the login
+// security extension (RFC 8807).
+func GeneratePassword(n int) (string, error) {
+	if n < 6 {
+		return "", errPasswordLength
+	}
+	classes := []string{passwordLower, passwordUpper, passwordDigits, passwordSymbols}
+	all := passwordLower + passwordUpper + passwordDigits + passwordSymbols
+	p := make([]byte, n)
+	for i := range p {
+		chars := all
+		if i < len(classes) {
+			chars = classes[i]
+		}
+		j, err := randInt(len(chars))
+		if err != nil {
+			return "", err
+		}
+		p[i] = chars[j]
+	}
+	// Shuffle, so the required classes are not always first.
+	for i := len(p) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		p[i], p[j] = p[j], p[i]
+	}
+	return string(p), nil
+}
+
+// randInt returns a uniform random integer in [0, n).
+func randInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
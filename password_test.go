@@ -0,0 +1,331 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+// testRotationServer is a fake EPP server that responds to each
+// login with a response returned by login.
+type testRotationServer struct {
+	dials atomic.Int32
+
+	// greeting, if set, is sent instead of testXMLGreeting.
+	greeting string
+
+	// login is called with each login request x, and returns a response.
+	login func(x string) string
+
+	m      sync.Mutex
+	newPWs []string
+}
+
+func (s *testRotationServer) dial(ctx context.Context) (*Conn, error) {
+	s.dials.Add(1)
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		g := s.greeting
+		if g == "" {
+			g = testXMLGreeting
+		}
+		err := writeDataUnit(server, []byte(g))
+		if err != nil {
+			return
+		}
+		for {
+			x, err := readTestRequest(server)
+			if err != nil {
+				return
+			}
+			res := testXMLResponse(ResultSuccessEndingSession, "")
+			if strings.Contains(x, "<login>") {
+				if pw := testElement(x, "newPW"); pw != "" {
+					s.m.Lock()
+					s.newPWs = append(s.newPWs, pw)
+					s.m.Unlock()
+				}
+				res = s.login(x)
+				if res == "" {
+					return
+				}
+			}
+			err = writeDataUnit(server, []byte(res))
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return NewConn(client)
+}
+
+// testStore is an in-memory CredentialStore.
+type testStore struct {
+	m                       sync.Mutex
+	user, password, pending string
+	err                     error // returned by SetPassword
+}
+
+func (s *testStore) Credentials(ctx context.Context) (string, string, string, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.user, s.password, s.pending, nil
+}
+
+func (s *testStore) SetPendingPassword(ctx context.Context, password string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.pending = password
+	return nil
+}
+
+func (s *testStore) SetPassword(ctx context.Context, password string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.password = password
+	s.pending = ""
+	return nil
+}
+
+func TestPasswordRotatorNoChange(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		return testXMLLoginResponse
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	c, err := r.Open(context.Background())
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	st.Expect(t, s.dials.Load(), int32(1))
+	st.Expect(t, len(s.newPWs), 0)
+	st.Expect(t, store.password, "battery")
+}
+
+func TestPasswordRotatorExpired(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "newPW") == "" {
+			return testXMLPasswordExpiredResponse
+		}
+		return testXMLLoginResponse
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store, NewPassword: func() (string, error) {
+		return "Horse-Staple-9", nil
+	}}
+	c, err := r.Open(context.Background())
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	// The login is retried on the same connection.
+	st.Expect(t, s.dials.Load(), int32(1))
+	st.Expect(t, s.newPWs, []string{"Horse-Staple-9"})
+	st.Expect(t, store.password, "Horse-Staple-9")
+}
+
+func TestPasswordRotatorExpiryWarning(t *testing.T) {
+	s := &testRotationServer{
+		greeting: strings.Replace(testXMLGreeting, "<extURI>", "<extURI>"+ExtLoginSec+"</extURI><extURI>", 1),
+		login: func(x string) string {
+			st.Expect(t, testElement(x, "loginSec:pw"), "battery")
+			if testElement(x, "loginSec:newPW") == "" {
+				return strings.Replace(testXMLLoginSecResponse, "<clTRID>ABC-12345</clTRID>", "", 1)
+			}
+			return testXMLLoginResponse
+		},
+	}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{
+		Dial:         s.dial,
+		Store:        store,
+		LoginOptions: &LoginOptions{Extensions: []string{ExtLoginSec}},
+		NewPassword: func() (string, error) {
+			return GeneratePassword(32)
+		},
+	}
+	c, err := r.Open(context.Background())
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	// The password is changed in a new session.
+	st.Expect(t, s.dials.Load(), int32(2))
+	st.Expect(t, s.newPWs, []string{loginSecPassword})
+	st.Expect(t, len(store.password), 32)
+}
+
+func TestPasswordRotatorTooLong(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		return testXMLPasswordExpiredResponse
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store, NewPassword: func() (string, error) {
+		return GeneratePassword(17)
+	}}
+	_, err := r.Open(context.Background())
+	st.Expect(t, err, errPasswordTooLong)
+	st.Expect(t, len(s.newPWs), 0)
+	st.Expect(t, store.password, "battery")
+}
+
+func TestPasswordRotatorStoreError(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "newPW") == "" {
+			return testXMLPasswordExpiredResponse
+		}
+		return testXMLLoginResponse
+	}}
+	errStore := errors.New("disk full")
+	store := &testStore{user: "jane", password: "battery", err: errStore}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	_, err := r.Open(context.Background())
+	var pse *PasswordChangeError
+	st.Assert(t, errors.As(err, &pse), true)
+	st.Expect(t, errors.Is(err, errStore), true)
+	st.Expect(t, s.newPWs, []string{pse.Password})
+	st.Expect(t, strings.Contains(err.Error(), pse.Password), false)
+}
+
+func TestPasswordRotatorConnectionLost(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "newPW") == "" {
+			return testXMLPasswordExpiredResponse
+		}
+		// Hang up after reading the new password.
+		return ""
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	_, err := r.Open(context.Background())
+	var pce *PasswordChangeError
+	st.Assert(t, errors.As(err, &pce), true)
+	st.Expect(t, s.newPWs, []string{pce.Password})
+	st.Expect(t, store.password, "battery")
+	st.Expect(t, store.pending, pce.Password)
+}
+
+func TestPasswordRotatorPending(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "pw") != "Horse-Staple-9" {
+			return testXMLResponse(ResultAuthenticationError, "")
+		}
+		return testXMLLoginResponse
+	}}
+	// The server accepted the pending password, but it was not saved.
+	store := &testStore{user: "jane", password: "battery", pending: "Horse-Staple-9"}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	c, err := r.Open(context.Background())
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	st.Expect(t, s.dials.Load(), int32(1))
+	st.Expect(t, len(s.newPWs), 0)
+	st.Expect(t, store.password, "Horse-Staple-9")
+	st.Expect(t, store.pending, "")
+
+	// The server did not accept the pending password.
+	store = &testStore{user: "jane", password: "Horse-Staple-9", pending: "battery"}
+	r = &PasswordRotator{Dial: s.dial, Store: store}
+	c, err = r.Open(context.Background())
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+	st.Expect(t, s.dials.Load(), int32(2))
+	st.Expect(t, store.password, "Horse-Staple-9")
+	st.Expect(t, store.pending, "")
+}
+
+func TestPasswordRotatorRejected(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "newPW") == "" {
+			return testXMLPasswordExpiredResponse
+		}
+		return testXMLResponse(ResultParameterValuePolicyError, "")
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	_, err := r.Open(context.Background())
+	st.Expect(t, err.(*Result).Code, ResultParameterValuePolicyError)
+	st.Expect(t, store.password, "battery")
+	st.Expect(t, store.pending, "")
+}
+
+func TestPasswordRotatorPool(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "pw") == "battery" && testElement(x, "newPW") == "" {
+			return testXMLPasswordExpiredResponse
+		}
+		return testXMLLoginResponse
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	p := &Pool{Open: r.Open, MaxSessions: 2}
+	defer p.Close()
+	c1, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	st.Reject(t, store.password, "battery")
+	// New sessions log in with the rotated password.
+	c2, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c1, nil)
+	p.Put(c2, nil)
+	st.Expect(t, len(s.newPWs), 1)
+	st.Expect(t, s.dials.Load(), int32(2))
+}
+
+func TestPasswordRotatorConcurrent(t *testing.T) {
+	s := &testRotationServer{login: func(x string) string {
+		if testElement(x, "pw") == "battery" && testElement(x, "newPW") == "" {
+			return testXMLPasswordExpiredResponse
+		}
+		return testXMLLoginResponse
+	}}
+	store := &testStore{user: "jane", password: "battery"}
+	r := &PasswordRotator{Dial: s.dial, Store: store}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := r.Open(context.Background())
+			st.Expect(t, err, nil)
+			if err == nil {
+				c.Conn.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	// The password is changed once.
+	st.Expect(t, len(s.newPWs), 1)
+	st.Expect(t, store.password, s.newPWs[0])
+}
+
+func TestGeneratePassword(t *testing.T) {
+	_, err := GeneratePassword(5)
+	st.Expect(t, err, errPasswordLength)
+	for _, n := range []int{6, 16, 64} {
+		p, err := GeneratePassword(n)
+		st.Expect(t, err, nil)
+		st.Expect(t, len(p), n)
+		st.Expect(t, strings.ContainsAny(p, passwordLower), true)
+		st.Expect(t, strings.ContainsAny(p, passwordUpper), true)
+		st.Expect(t, strings.ContainsAny(p, passwordDigits), true)
+		st.Expect(t, strings.ContainsAny(p, passwordSymbols), true)
+	}
+}
+
+var testXMLPasswordExpiredResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="2200">
+			<msg>Authentication error; password expired</msg>
+		</result>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
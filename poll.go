@@ -0,0 +1,253 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+var errMissingMessageID = errors.New("epp: missing poll message ID")
+
+// MessageQueue represents an EPP <msgQ> element, describing
+// the state of the client's service message queue.
+// https://tools.ietf.org/html/rfc5730#section-2.6
+type MessageQueue struct {
+	Count    int       // count attribute, the number of messages in the queue
+	ID       string    // id attribute, identifying the message at the head of the queue
+	QDate    time.Time // <qDate>, when the message was enqueued
+	Message  string    // <msg>, human-readable message text
+	Language string    // <msg lang="...">
+}
+
+// PendingAction represents the result of an offline action, reported to the
+// client in a <panData> element of a service message.
+// https://tools.ietf.org/html/rfc5731#section-3.3
+type PendingAction struct {
+	Object              string    // <domain:name>, <host:name> or <contact:id>
+	Approved            bool      // paResult attribute
+	ClientTransactionID string    // <paTRID><clTRID>
+	ServerTransactionID string    // <paTRID><svTRID>
+	Date                time.Time // <paDate>
+}
+
+// PollMessage represents a service message retrieved from the server message queue.
+// Object-specific fields are set if the message contains the corresponding
+// <resData> element, and nil otherwise.
+type PollMessage struct {
+	MessageQueue
+
+	DomainTransfer       *DomainTransferResponse  // <domain:trnData>
+	ContactTransfer      *ContactTransferResponse // <contact:trnData>
+	DomainPendingAction  *PendingAction           // <domain:panData>
+	HostPendingAction    *PendingAction           // <host:panData>
+	ContactPendingAction *PendingAction           // <contact:panData>
+
+	// Response is the complete response containing the message,
+	// for access to data not mapped above.
+	Response *Response
+}
+
+// PollRequest retrieves the message at the head of the server message queue.
+// The message remains queued until acknowledged with PollAck.
+// It returns nil and no error if the queue is empty (result code 1300).
+// https://tools.ietf.org/html/rfc5730#section-2.9.2.3
+func (c *Conn) PollRequest() (*PollMessage, error) {
+	return c.PollRequestContext(context.Background())
+}
+
+// PollRequestContext is like PollRequest, but aborts if ctx is done before the server responds.
+func (c *Conn) PollRequestContext(ctx context.Context) (*PollMessage, error) {
+	tx, err := c.writeRequest(ctx, []byte(xmlPollRequest))
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if res.Result.Code == ResultSuccessNoMessages {
+		return nil, nil
+	}
+	return newPollMessage(res), nil
+}
+
+// PollAck acknowledges receipt of the message identified by id, removing it
+// from the server message queue. The returned PollMessage reports the
+// number of messages remaining in the queue.
+// https://tools.ietf.org/html/rfc5730#section-2.9.2.3
+func (c *Conn) PollAck(id string) (*PollMessage, error) {
+	return c.PollAckContext(context.Background(), id)
+}
+
+// PollAckContext is like PollAck, but aborts if ctx is done before the server responds.
+func (c *Conn) PollAckContext(ctx context.Context, id string) (*PollMessage, error) {
+	x, err := encodePollAck(id)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return newPollMessage(res), nil
+}
+
+func newPollMessage(res *Response) *PollMessage {
+	msg := &PollMessage{
+		MessageQueue: res.MessageQueue,
+		Response:     res,
+	}
+	if res.DomainTransfer.Domain != "" {
+		msg.DomainTransfer = &res.DomainTransfer
+	}
+	if res.ContactTransfer.ID != "" {
+		msg.ContactTransfer = &res.ContactTransfer
+	}
+	if res.DomainPendingAction.Object != "" {
+		msg.DomainPendingAction = &res.DomainPendingAction
+	}
+	if res.HostPendingAction.Object != "" {
+		msg.HostPendingAction = &res.HostPendingAction
+	}
+	if res.ContactPendingAction.Object != "" {
+		msg.ContactPendingAction = &res.ContactPendingAction
+	}
+	return msg
+}
+
+// Default PollConsumer backoff intervals.
+const (
+	DefaultPollBackoff    = time.Minute
+	DefaultPollMaxBackoff = 15 * time.Minute
+)
+
+// PollConsumer repeatedly drains the server message queue on Conn,
+// passing each message to Handler. A message is acknowledged only after
+// Handler returns successfully. When the queue is empty, PollConsumer waits
+// before polling again, doubling the wait from Backoff up to MaxBackoff
+// until a message arrives.
+type PollConsumer struct {
+	Conn    *Conn
+	Handler func(context.Context, *PollMessage) error
+
+	// Backoff is the initial wait after finding the queue empty.
+	// If zero, DefaultPollBackoff is used.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum wait between polls of an empty queue.
+	// If zero, DefaultPollMaxBackoff is used.
+	MaxBackoff time.Duration
+}
+
+// Run consumes messages until ctx is done or an error occurs. If Handler
+// returns an error, the message is left in the queue and Run returns the error.
+// Run always returns a non-nil error.
+func (pc *PollConsumer) Run(ctx context.Context) error {
+	backoff := pc.Backoff
+	if backoff <= 0 {
+		backoff = DefaultPollBackoff
+	}
+	maxBackoff := pc.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultPollMaxBackoff
+	}
+	wait := backoff
+	for {
+		msg, err := pc.Conn.PollRequestContext(ctx)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+			wait = min(wait*2, maxBackoff)
+			continue
+		}
+		wait = backoff
+		err = pc.Handler(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("epp: poll message %s: %w", msg.ID, err)
+		}
+		_, err = pc.Conn.PollAckContext(ctx, msg.ID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+var xmlPollRequest = xmlCommandPrefix + `<poll op="req"/>` + xmlCommandSuffix
+
+func encodePollAck(id string) ([]byte, error) {
+	if id == "" {
+		return nil, errMissingMessageID
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<poll op="ack" msgID="`)
+	xml.EscapeText(buf, []byte(id))
+	buf.WriteString(`"/>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func init() {
+	path := "epp > response > msgQ"
+	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+		mq := &c.Value.(*Response).MessageQueue
+		mq.Count = c.AttrInt("", "count")
+		mq.ID = c.Attr("", "id")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">qDate", func(c *xx.Context) error {
+		mq := &c.Value.(*Response).MessageQueue
+		var err error
+		mq.QDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">msg", func(c *xx.Context) error {
+		mq := &c.Value.(*Response).MessageQueue
+		mq.Message = string(c.CharData)
+		mq.Language = c.Attr("", "lang")
+		return nil
+	})
+
+	scanPendingAction := func(ns, object string, pa func(res *Response) *PendingAction) {
+		path := "epp > response > resData > " + ns + " panData"
+		scanResponse.MustHandleCharData(path+">"+object, func(c *xx.Context) error {
+			p := pa(c.Value.(*Response))
+			p.Object = string(c.CharData)
+			p.Approved = c.AttrBool("", "paResult")
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">paTRID>clTRID", func(c *xx.Context) error {
+			pa(c.Value.(*Response)).ClientTransactionID = string(c.CharData)
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">paTRID>svTRID", func(c *xx.Context) error {
+			pa(c.Value.(*Response)).ServerTransactionID = string(c.CharData)
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">paDate", func(c *xx.Context) error {
+			p := pa(c.Value.(*Response))
+			var err error
+			p.Date, err = time.Parse(time.RFC3339, string(c.CharData))
+			return err
+		})
+	}
+	scanPendingAction(ObjDomain, "name", func(res *Response) *PendingAction { return &res.DomainPendingAction })
+	scanPendingAction(ObjHost, "name", func(res *Response) *PendingAction { return &res.HostPendingAction })
+	scanPendingAction(ObjContact, "id", func(res *Response) *PendingAction { return &res.ContactPendingAction })
+}
@@ -0,0 +1,385 @@
+package epp
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodePoll(t *testing.T) {
+	st.Expect(t, xmlPollRequest, `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><poll op="req"/></command></epp>`)
+
+	x, err := encodePollAck("12345")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><poll op="ack" msgID="12345"/></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	_, err = encodePollAck("")
+	st.Expect(t, err, errMissingMessageID)
+}
+
+func TestScanPollTransferResponse(t *testing.T) {
+	res, err := scanDataUnit([]byte(testXMLPollTransferResponse))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Result.Code, ResultSuccessAckToDequeue)
+
+	msg := newPollMessage(res)
+	st.Expect(t, msg.Count, 5)
+	st.Expect(t, msg.ID, "12345")
+	st.Expect(t, msg.QDate, time.Date(2000, 6, 8, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, msg.Message, "Transfer requested.")
+	st.Expect(t, msg.Language, "en")
+	st.Assert(t, msg.DomainTransfer != nil, true)
+	st.Expect(t, msg.DomainTransfer.Domain, "example.com")
+	st.Expect(t, msg.DomainTransfer.Status, "pending")
+	st.Expect(t, msg.DomainTransfer.ReID, "ClientX")
+	st.Expect(t, msg.ContactTransfer, (*ContactTransferResponse)(nil))
+	st.Expect(t, msg.DomainPendingAction, (*PendingAction)(nil))
+}
+
+func TestScanPollPendingActionResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1301">
+			<msg>Command completed successfully; ack to dequeue</msg>
+		</result>
+		<msgQ count="1" id="12346">
+			<qDate>1999-04-04T22:01:00.0Z</qDate>
+			<msg>Pending action completed successfully.</msg>
+		</msgQ>
+		<resData>
+			<domain:panData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name paResult="1">example.com</domain:name>
+				<domain:paTRID>
+					<clTRID>ABC-12345</clTRID>
+					<svTRID>54321-XYZ</svTRID>
+				</domain:paTRID>
+				<domain:paDate>1999-04-04T22:00:00.0Z</domain:paDate>
+			</domain:panData>
+		</resData>
+		<trID>
+			<clTRID>BCD-23456</clTRID>
+			<svTRID>65432-WXY</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	res, err := scanDataUnit([]byte(x))
+	st.Expect(t, err, nil)
+	msg := newPollMessage(res)
+	st.Expect(t, msg.Count, 1)
+	st.Expect(t, msg.ID, "12346")
+	st.Expect(t, msg.DomainPendingAction, &PendingAction{
+		Object:              "example.com",
+		Approved:            true,
+		ClientTransactionID: "ABC-12345",
+		ServerTransactionID: "54321-XYZ",
+		Date:                time.Date(1999, 4, 4, 22, 0, 0, 0, time.UTC),
+	})
+	st.Expect(t, res.Result.ClientTransactionID, "BCD-23456")
+	st.Expect(t, res.Result.ServerTransactionID, "65432-WXY")
+}
+
+func TestScanPollHostPendingActionResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1301">
+			<msg>Command completed successfully; ack to dequeue</msg>
+		</result>
+		<msgQ count="1" id="12346">
+			<qDate>1999-04-04T22:01:00.0Z</qDate>
+			<msg>Pending action completed successfully.</msg>
+		</msgQ>
+		<resData>
+			<host:panData xmlns:host="urn:ietf:params:xml:ns:host-1.0">
+				<host:name paResult="0">ns1.example.com</host:name>
+				<host:paTRID>
+					<clTRID>ABC-12345</clTRID>
+					<svTRID>54321-XYZ</svTRID>
+				</host:paTRID>
+				<host:paDate>1999-04-04T22:00:00.0Z</host:paDate>
+			</host:panData>
+		</resData>
+		<trID>
+			<clTRID>BCD-23456</clTRID>
+			<svTRID>65432-WXY</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	res, err := scanDataUnit([]byte(x))
+	st.Expect(t, err, nil)
+	msg := newPollMessage(res)
+	st.Expect(t, msg.DomainPendingAction, (*PendingAction)(nil))
+	st.Expect(t, msg.HostPendingAction, &PendingAction{
+		Object:              "ns1.example.com",
+		Approved:            false,
+		ClientTransactionID: "ABC-12345",
+		ServerTransactionID: "54321-XYZ",
+		Date:                time.Date(1999, 4, 4, 22, 0, 0, 0, time.UTC),
+	})
+}
+
+func TestPollRequestAndAck(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		x, err := readTestRequest(conn)
+		st.Assert(t, err, nil)
+		st.Expect(t, strings.Contains(x, `<poll op="req"/>`), true)
+		err = writeDataUnit(conn, []byte(testXMLPollTransferResponse))
+		st.Assert(t, err, nil)
+		x, err = readTestRequest(conn)
+		st.Assert(t, err, nil)
+		st.Expect(t, strings.Contains(x, `<poll op="ack" msgID="12345"/>`), true)
+		err = writeDataUnit(conn, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<msgQ count="4" id="12345"/>
+	</response>
+</epp>`))
+		st.Assert(t, err, nil)
+		_, err = readTestRequest(conn)
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLPollEmptyResponse))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	msg, err := c.PollRequest()
+	st.Assert(t, err, nil)
+	st.Expect(t, msg.ID, "12345")
+	st.Expect(t, msg.DomainTransfer.Domain, "example.com")
+
+	msg, err = c.PollAck(msg.ID)
+	st.Assert(t, err, nil)
+	st.Expect(t, msg.Count, 4)
+
+	msg, err = c.PollRequest()
+	st.Expect(t, err, nil)
+	st.Expect(t, msg, (*PollMessage)(nil))
+}
+
+func TestPollConsumer(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		for _, r := range []struct{ req, res string }{
+			{`<poll op="req"/>`, testXMLPollTransferResponse},
+			{`<poll op="ack" msgID="12345"/>`, testXMLPollEmptyResponse},
+			{`<poll op="req"/>`, testXMLPollEmptyResponse},
+			{`<poll op="req"/>`, testXMLPollTransferResponse},
+		} {
+			x, err := readTestRequest(conn)
+			st.Assert(t, err, nil)
+			st.Expect(t, strings.Contains(x, r.req), true)
+			err = writeDataUnit(conn, []byte(r.res))
+			st.Assert(t, err, nil)
+		}
+		// The consumer must not ack a message its handler failed to process.
+		_, err = readTestRequest(conn)
+		st.Expect(t, err, io.EOF)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	errHandler := errors.New("handler failed")
+	var handled []string
+	pc := &PollConsumer{
+		Conn: c,
+		Handler: func(ctx context.Context, msg *PollMessage) error {
+			handled = append(handled, msg.ID)
+			if len(handled) > 1 {
+				return errHandler
+			}
+			return nil
+		},
+		Backoff: time.Millisecond,
+	}
+	err = pc.Run(context.Background())
+	st.Expect(t, errors.Is(err, errHandler), true)
+	st.Expect(t, handled, []string{"12345", "12345"})
+	c.Conn.Close()
+}
+
+func TestPollConsumerContextDone(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		_, err = readTestRequest(conn)
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLPollEmptyResponse))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	pc := &PollConsumer{
+		Conn: c,
+		Handler: func(ctx context.Context, msg *PollMessage) error {
+			t.Error("unexpected message")
+			return nil
+		},
+	}
+	err = pc.Run(ctx)
+	st.Expect(t, err, context.DeadlineExceeded)
+}
+
+func TestScanMessageQueueAnyResponse(t *testing.T) {
+	res, err := scanDataUnit([]byte(testXMLMessageQueueResponse(3)))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Result.Code, ResultSuccess)
+	st.Expect(t, res.MessageQueue.Count, 3)
+	st.Expect(t, res.MessageQueue.ID, "12345")
+	st.Expect(t, len(res.DomainCheckResponse.Checks), 1)
+}
+
+func TestNotifyMessageQueue(t *testing.T) {
+	responses := []string{
+		testXMLMessageQueueResponse(2),
+		testXMLMessageQueueResponse(2),
+		testXMLMessageQueueResponse(3),
+		testXMLPollEmptyResponse,
+		testXMLMessageQueueResponse(1),
+	}
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		for _, x := range responses {
+			_, err = readTestRequest(conn)
+			st.Assert(t, err, nil)
+			err = writeDataUnit(conn, []byte(x))
+			st.Assert(t, err, nil)
+		}
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	ch := make(chan MessageQueue, len(responses))
+	c.NotifyMessageQueue(ch)
+	for range responses {
+		_, err = c.CheckDomain("example.com")
+		st.Assert(t, err, nil)
+	}
+	close(ch)
+	var counts []int
+	for mq := range ch {
+		counts = append(counts, mq.Count)
+	}
+	st.Expect(t, counts, []int{2, 3, 1})
+}
+
+func testXMLMessageQueueResponse(count int) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<msgQ count="` + strconv.Itoa(count) + `" id="12345"/>
+		<resData>
+			<domain:chkData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:cd>
+					<domain:name avail="1">example.com</domain:name>
+				</domain:cd>
+			</domain:chkData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+}
+
+var testXMLPollTransferResponse = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1301">
+			<msg>Command completed successfully; ack to dequeue</msg>
+		</result>
+		<msgQ count="5" id="12345">
+			<qDate>2000-06-08T22:00:00.0Z</qDate>
+			<msg lang="en">Transfer requested.</msg>
+		</msgQ>
+		<resData>
+			<domain:trnData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>example.com</domain:name>
+				<domain:trStatus>pending</domain:trStatus>
+				<domain:reID>ClientX</domain:reID>
+				<domain:reDate>2000-06-08T22:00:00.0Z</domain:reDate>
+				<domain:acID>ClientY</domain:acID>
+				<domain:acDate>2000-06-13T22:00:00.0Z</domain:acDate>
+				<domain:exDate>2002-09-08T22:00:00.0Z</domain:exDate>
+			</domain:trnData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+var testXMLPollEmptyResponse = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1300">
+			<msg>Command completed successfully; no messages</msg>
+		</result>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
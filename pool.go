@@ -0,0 +1,313 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errPoolClosed = errors.New("epp: pool closed")
+
+// logoutTimeout limits the time a Pool waits for the
+// server to respond to a <logout> when closing a session.
+const logoutTimeout = 10 * time.Second
+
+// minIdleCheck is the minimum interval between checks for idle sessions.
+const minIdleCheck = time.Millisecond
+
+// maxIdleCheck is the maximum interval between checks for idle or closed
+// sessions, and for fewer than MinSessions open sessions.
+const maxIdleCheck = 10 * time.Second
+
+// Pool maintains a set of logged-in EPP sessions with a single server,
+// limiting the number of concurrent sessions. It is safe for concurrent use.
+// The exported fields must be set before the first call to Get and not modified after.
+type Pool struct {
+	// Dial opens a new EPP connection, with the server greeting read.
+	Dial func(ctx context.Context) (*Conn, error)
+
+	// User and Password are the credentials used to log in each new session.
+	User     string
+	Password string
+
+	// LoginOptions, if set, are used to log in each session.
+	LoginOptions *LoginOptions
+
+	// Open, if set, opens a new logged-in session, and is used instead of
+	// Dial, User, Password and LoginOptions. For example, set Open to the
+	// Open method of a PasswordRotator to log in with rotated credentials.
+	Open func(ctx context.Context) (*Conn, error)
+
+	// MinSessions is the number of logged-in sessions kept open, up to
+	// MaxSessions. After the first call to Get, the pool opens sessions in
+	// the background until MinSessions are open, and replaces idle sessions
+	// that are closed. Idle sessions are not closed after IdleTimeout while
+	// MinSessions or fewer are open. Errors opening sessions are ignored,
+	// and opening is retried periodically.
+	MinSessions int
+
+	// MaxSessions limits the number of open sessions. Get blocks
+	// while the limit is reached and all sessions are in use.
+	// If zero, the pool opens at most one session.
+	MaxSessions int
+
+	// IdleTimeout is how long a session may remain idle before it is
+	// closed, unless no more than MinSessions are open. If zero, idle
+	// sessions are not closed.
+	IdleTimeout time.Duration
+
+	// Limiter, if set, limits the rate of commands sent across all
+	// sessions in the pool, e.g. to enforce a per-registry limit.
+	Limiter RateLimiter
+
+	// logoutTimeout, if set, overrides the package logoutTimeout for testing.
+	logoutTimeout time.Duration
+
+	once sync.Once
+	sem  chan struct{} // holds a token for each open session
+	idle chan idleConn // idle sessions, logged in
+
+	// mDone serializes closing done with sends on idle.
+	mDone sync.Mutex
+	done  chan struct{} // closed by Close
+}
+
+// idleConn is a session returned to a Pool, and when it was returned.
+type idleConn struct {
+	c     *Conn
+	since time.Time
+}
+
+func (p *Pool) init() {
+	p.once.Do(func() {
+		max := p.MaxSessions
+		if max <= 0 {
+			max = 1
+		}
+		p.sem = make(chan struct{}, max)
+		p.idle = make(chan idleConn, max)
+		p.done = make(chan struct{})
+		if p.IdleTimeout > 0 || p.MinSessions > 0 {
+			go p.maintain()
+		}
+	})
+}
+
+// Get returns a logged-in session from p, opening a new one if none are
+// idle and fewer than MaxSessions are open. The caller must return the
+// session to p with Put when done.
+func (p *Pool) Get(ctx context.Context) (*Conn, error) {
+	p.init()
+	select {
+	case <-p.done:
+		return nil, errPoolClosed
+	default:
+	}
+	for {
+		select {
+		case ic := <-p.idle:
+			if ic.c.closed() {
+				p.release()
+				continue
+			}
+			return ic.c, nil
+		default:
+		}
+		select {
+		case ic := <-p.idle:
+			if ic.c.closed() {
+				p.release()
+				continue
+			}
+			return ic.c, nil
+		case p.sem <- struct{}{}:
+			c, err := openSession(ctx, p.Open, p.Dial, p.User, p.Password, p.LoginOptions, p.Limiter)
+			if err != nil {
+				<-p.sem
+				return nil, err
+			}
+			return c, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.done:
+			return nil, errPoolClosed
+		}
+	}
+}
+
+// Put returns session c, obtained from Get, to p. The err argument is the
+// error (if any) returned by the last command on c. If err is a fatal
+// Result (code 2500 or higher) or the connection is closed, c is discarded
+// and a new session is opened by a subsequent call to Get.
+func (p *Pool) Put(c *Conn, err error) {
+	if sessionLost(c, err) {
+		c.Conn.Close()
+		p.release()
+		return
+	}
+	if !p.putIdle(c) {
+		p.discard(c)
+	}
+}
+
+// putIdle adds c to the idle sessions in p,
+// returning false if p is closed.
+func (p *Pool) putIdle(c *Conn) bool {
+	p.mDone.Lock()
+	defer p.mDone.Unlock()
+	select {
+	case <-p.done:
+		return false
+	default:
+		p.idle <- idleConn{c: c, since: time.Now()}
+		return true
+	}
+}
+
+// Do calls f with a session from p, returning it to p afterwards.
+// It returns the error returned by f.
+func (p *Pool) Do(ctx context.Context, f func(*Conn) error) error {
+	c, err := p.Get(ctx)
+	if err != nil {
+		return err
+	}
+	err = f(c)
+	p.Put(c, err)
+	return err
+}
+
+// Close logs out and closes all idle sessions. Sessions in use are closed
+// when returned with Put. Subsequent calls to Get return an error.
+func (p *Pool) Close() error {
+	p.init()
+	conns, err := p.closeDone()
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for _, c := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.discard(c)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// closeDone closes p.done, and removes and returns the idle sessions.
+func (p *Pool) closeDone() ([]*Conn, error) {
+	p.mDone.Lock()
+	defer p.mDone.Unlock()
+	select {
+	case <-p.done:
+		return nil, errPoolClosed
+	default:
+	}
+	close(p.done)
+	var conns []*Conn
+	for {
+		select {
+		case ic := <-p.idle:
+			conns = append(conns, ic.c)
+		default:
+			return conns, nil
+		}
+	}
+}
+
+// discard logs out and closes session c, and frees its slot.
+// It waits at most logoutTimeout for the server to respond.
+func (p *Pool) discard(c *Conn) {
+	timeout := logoutTimeout
+	if p.logoutTimeout > 0 {
+		timeout = p.logoutTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c.close(ctx)
+	p.release()
+}
+
+// release frees the slot held by a closed session.
+func (p *Pool) release() {
+	<-p.sem
+}
+
+// maintain periodically closes sessions idle longer than p.IdleTimeout,
+// and opens sessions until p.MinSessions are open, until p is closed.
+func (p *Pool) maintain() {
+	interval := maxIdleCheck
+	if p.IdleTimeout > 0 {
+		interval = min(max(p.IdleTimeout/2, minIdleCheck), maxIdleCheck)
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		p.fill()
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+		}
+		for _, c := range p.expired() {
+			p.discard(c)
+		}
+	}
+}
+
+// fill opens idle sessions until p.MinSessions are open,
+// stopping at the first error or when p is closed.
+func (p *Pool) fill() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for len(p.sem) < p.MinSessions {
+		select {
+		case p.sem <- struct{}{}:
+		default:
+			return
+		}
+		c, err := openSession(ctx, p.Open, p.Dial, p.User, p.Password, p.LoginOptions, p.Limiter)
+		if err != nil {
+			<-p.sem
+			return
+		}
+		if !p.putIdle(c) {
+			p.discard(c)
+			return
+		}
+	}
+}
+
+// expired removes and returns idle sessions that are closed, or have
+// exceeded p.IdleTimeout, leaving at least p.MinSessions open.
+func (p *Pool) expired() []*Conn {
+	p.mDone.Lock()
+	defer p.mDone.Unlock()
+	var conns []*Conn
+	open := len(p.sem)
+	for n := len(p.idle); n > 0; n-- {
+		var ic idleConn
+		select {
+		case ic = <-p.idle:
+		default:
+			return conns
+		}
+		if ic.c.closed() || p.IdleTimeout > 0 && open > p.MinSessions && time.Since(ic.since) >= p.IdleTimeout {
+			conns = append(conns, ic.c)
+			open--
+			continue
+		}
+		p.idle <- ic
+	}
+	return conns
+}
@@ -0,0 +1,233 @@
+package epp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+// testPoolServer counts connections and logins to fake EPP servers.
+type testPoolServer struct {
+	dials  atomic.Int32
+	logins atomic.Int32
+
+	// hangLogout causes the server to never respond to <logout>.
+	hangLogout bool
+
+	// fail, if set, is called for each command x other than login
+	// and logout on the nth connection. It returns a result code to
+	// respond with, or 0 to close the connection without responding.
+	fail func(n int32, x string) int
+}
+
+// dial opens a connection to a new fake EPP server over a pipe.
+func (s *testPoolServer) dial(ctx context.Context) (*Conn, error) {
+	n := s.dials.Add(1)
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		err := writeDataUnit(server, []byte(testXMLGreeting))
+		if err != nil {
+			return
+		}
+		for {
+			x, err := readTestRequest(server)
+			if err != nil {
+				return
+			}
+			code := ResultSuccess
+			switch {
+			case strings.Contains(x, "<login>"):
+				s.logins.Add(1)
+			case strings.Contains(x, "<logout/>") && s.hangLogout:
+				continue
+			case strings.Contains(x, "<logout/>"):
+				code = ResultSuccessEndingSession
+			case s.fail != nil:
+				code = s.fail(n, x)
+				if code == 0 {
+					return
+				}
+			}
+			err = writeDataUnit(server, []byte(testXMLResponse(code, testElement(x, "clTRID"))))
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return NewConn(client)
+}
+
+func TestPoolReuse(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", MaxSessions: 2}
+	defer p.Close()
+
+	c1, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c1, nil)
+	c2, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	st.Expect(t, c2, c1)
+	p.Put(c2, nil)
+	st.Expect(t, s.dials.Load(), int32(1))
+	st.Expect(t, s.logins.Load(), int32(1))
+}
+
+func TestPoolMaxSessions(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", MaxSessions: 1}
+	defer p.Close()
+
+	c1, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = p.Get(ctx)
+	st.Expect(t, err, context.DeadlineExceeded)
+
+	time.AfterFunc(10*time.Millisecond, func() { p.Put(c1, nil) })
+	c2, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	st.Expect(t, c2, c1)
+	p.Put(c2, nil)
+	st.Expect(t, s.dials.Load(), int32(1))
+}
+
+func TestPoolDiscardFatal(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", MaxSessions: 1}
+	defer p.Close()
+
+	err := p.Do(context.Background(), func(c *Conn) error {
+		return &Result{Code: ResultCommandFailedServerClosingConnection}
+	})
+	st.Expect(t, err.(*Result).Code, ResultCommandFailedServerClosingConnection)
+
+	c, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c, &Result{Code: ResultObjectExists})
+	st.Expect(t, s.dials.Load(), int32(2))
+	st.Expect(t, s.logins.Load(), int32(2))
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", MaxSessions: 1, IdleTimeout: 10 * time.Millisecond}
+	defer p.Close()
+
+	c, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c, nil)
+	select {
+	case <-c.readDone:
+	case <-time.After(time.Second):
+		t.Fatal("idle session not closed")
+	}
+}
+
+func TestPoolShortIdleTimeout(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", IdleTimeout: time.Nanosecond}
+	defer p.Close()
+
+	c, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c, nil)
+	select {
+	case <-c.readDone:
+	case <-time.After(time.Second):
+		t.Fatal("idle session not closed")
+	}
+}
+
+func TestPoolMinSessions(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", MinSessions: 2, MaxSessions: 3, IdleTimeout: 10 * time.Millisecond}
+	defer p.Close()
+
+	c, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c, nil)
+	waitLogins := func(n int32) {
+		deadline := time.Now().Add(time.Second)
+		for s.logins.Load() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d logins, want %d", s.logins.Load(), n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitLogins(2)
+
+	// Idle sessions are kept open.
+	time.Sleep(50 * time.Millisecond)
+	st.Expect(t, s.logins.Load(), int32(2))
+	st.Expect(t, c.closed(), false)
+
+	// A closed idle session is replaced.
+	c.Conn.Close()
+	waitLogins(3)
+	st.Expect(t, s.logins.Load(), int32(3))
+}
+
+func TestPoolCloseUnresponsive(t *testing.T) {
+	s := testPoolServer{hangLogout: true}
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", MaxSessions: 2, logoutTimeout: 20 * time.Millisecond}
+	c1, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	c2, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c1, nil)
+	start := time.Now()
+	err = p.Close()
+	st.Expect(t, err, nil)
+	st.Expect(t, c1.closed(), true)
+
+	// Put on a closed pool closes the session without holding the pool lock.
+	done := make(chan struct{})
+	go func() {
+		p.Put(c2, nil)
+		close(done)
+	}()
+	_, err = p.Get(context.Background())
+	st.Expect(t, err, errPoolClosed)
+	<-done
+	st.Expect(t, c2.closed(), true)
+	st.Expect(t, time.Since(start) < logoutTimeout, true)
+}
+
+func TestPoolClose(t *testing.T) {
+	var s testPoolServer
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass"}
+	c, err := p.Get(context.Background())
+	st.Assert(t, err, nil)
+	p.Put(c, nil)
+	err = p.Close()
+	st.Expect(t, err, nil)
+	st.Expect(t, c.closed(), true)
+	_, err = p.Get(context.Background())
+	st.Expect(t, err, errPoolClosed)
+}
+
+func testXMLResponse(code int, clTRID string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="` + strconv.Itoa(code) + `">
+			<msg>Command completed</msg>
+		</result>
+		<trID>
+			<clTRID>` + clTRID + `</clTRID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+}
@@ -0,0 +1,127 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// CommandClass classifies EPP commands for rate limiting.
+type CommandClass int
+
+// Command classes.
+// https://tools.ietf.org/html/rfc5730#section-2.9
+const (
+	// CommandQuery is a session management or query command,
+	// such as <login>, <check>, <info>, <poll> or <transfer op="query">.
+	CommandQuery CommandClass = iota
+
+	// CommandTransform is an object transform command:
+	// <create>, <delete>, <renew>, <transfer> or <update>.
+	CommandTransform
+)
+
+// RateLimiter limits the rate at which commands are sent on a Conn.
+// A single RateLimiter may be shared by multiple connections, e.g. to
+// enforce a registry-wide limit across all sessions in a Pool.
+type RateLimiter interface {
+	// Wait blocks until a command of class may be sent, or ctx is done.
+	Wait(ctx context.Context, class CommandClass) error
+}
+
+// TokenBucket is a RateLimiter that allows Rate commands per second
+// on average, with bursts of up to Burst commands, regardless of class.
+// It is safe for concurrent use.
+type TokenBucket struct {
+	// Rate is the number of commands allowed per second.
+	// If zero or negative, commands are not limited.
+	Rate float64
+
+	// Burst is the maximum number of commands sent at once.
+	// If less than 1, it is treated as 1.
+	Burst int
+
+	m      sync.Mutex
+	tokens float64          // available tokens, negative if commands are waiting
+	last   time.Time        // when tokens was last updated
+	now    func() time.Time // returns the current time, if set, for testing
+}
+
+// Wait blocks until a token is available, or ctx is done.
+func (b *TokenBucket) Wait(ctx context.Context, _ CommandClass) error {
+	d := b.reserve()
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		b.m.Lock()
+		b.tokens++
+		b.m.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from b, returning how long to wait until it is available.
+func (b *TokenBucket) reserve() time.Duration {
+	if b.Rate <= 0 {
+		return 0
+	}
+	burst := float64(max(b.Burst, 1))
+	b.m.Lock()
+	defer b.m.Unlock()
+	now := time.Now()
+	if b.now != nil {
+		now = b.now()
+	}
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*b.Rate)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.Rate * float64(time.Second))
+}
+
+// CommandLimiter is a RateLimiter with separate budgets for query and
+// transform commands. A nil limiter does not limit its class of commands.
+type CommandLimiter struct {
+	Query     RateLimiter
+	Transform RateLimiter
+}
+
+// Wait blocks until the limiter for class allows a command, or ctx is done.
+func (l *CommandLimiter) Wait(ctx context.Context, class CommandClass) error {
+	rl := l.Query
+	if class == CommandTransform {
+		rl = l.Transform
+	}
+	if rl == nil {
+		return nil
+	}
+	return rl.Wait(ctx, class)
+}
+
+// commandClass returns the class of EPP command x.
+func commandClass(x []byte) CommandClass {
+	x = bytes.TrimPrefix(x, []byte(xmlCommandPrefix))
+	switch {
+	case bytes.HasPrefix(x, []byte(`<create>`)),
+		bytes.HasPrefix(x, []byte(`<delete>`)),
+		bytes.HasPrefix(x, []byte(`<renew>`)),
+		bytes.HasPrefix(x, []byte(`<update>`)):
+		return CommandTransform
+	case bytes.HasPrefix(x, []byte(`<transfer `)) && !bytes.HasPrefix(x, []byte(`<transfer op="query"`)):
+		return CommandTransform
+	}
+	return CommandQuery
+}
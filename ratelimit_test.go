@@ -0,0 +1,110 @@
+package epp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestCommandClass(t *testing.T) {
+	tests := []struct {
+		x     string
+		class CommandClass
+	}{
+		{xmlPollRequest, CommandQuery},
+		{string(xmlLogout), CommandQuery},
+		{xmlCommandPrefix + `<check><domain:check/></check>` + xmlCommandSuffix, CommandQuery},
+		{xmlCommandPrefix + `<info><domain:info/></info>` + xmlCommandSuffix, CommandQuery},
+		{xmlCommandPrefix + `<transfer op="query"><domain:transfer/></transfer>` + xmlCommandSuffix, CommandQuery},
+		{xmlCommandPrefix + `<transfer op="request"><domain:transfer/></transfer>` + xmlCommandSuffix, CommandTransform},
+		{xmlCommandPrefix + `<create><domain:create/></create>` + xmlCommandSuffix, CommandTransform},
+		{xmlCommandPrefix + `<delete><domain:delete/></delete>` + xmlCommandSuffix, CommandTransform},
+		{xmlCommandPrefix + `<renew><domain:renew/></renew>` + xmlCommandSuffix, CommandTransform},
+		{xmlCommandPrefix + `<update><domain:update/></update>` + xmlCommandSuffix, CommandTransform},
+	}
+	for _, tt := range tests {
+		st.Expect(t, commandClass([]byte(tt.x)), tt.class)
+	}
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := &TokenBucket{Rate: 100, Burst: 2, now: func() time.Time { return now }}
+	st.Expect(t, b.reserve(), time.Duration(0))
+	st.Expect(t, b.reserve(), time.Duration(0))
+	st.Expect(t, b.reserve(), 10*time.Millisecond)
+	st.Expect(t, b.reserve(), 20*time.Millisecond)
+	now = now.Add(30 * time.Millisecond)
+	st.Expect(t, b.reserve(), time.Duration(0))
+	// Tokens do not accumulate beyond Burst.
+	now = now.Add(time.Second)
+	st.Expect(t, b.reserve(), time.Duration(0))
+	st.Expect(t, b.reserve(), time.Duration(0))
+	st.Expect(t, b.reserve(), 10*time.Millisecond)
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := &TokenBucket{Rate: 100, Burst: 1}
+	ctx := context.Background()
+	err := b.Wait(ctx, CommandQuery)
+	st.Expect(t, err, nil)
+	start := time.Now()
+	err = b.Wait(ctx, CommandQuery)
+	st.Expect(t, err, nil)
+	st.Expect(t, time.Since(start) >= 5*time.Millisecond, true)
+}
+
+func TestTokenBucketContextDone(t *testing.T) {
+	b := &TokenBucket{Rate: 1}
+	err := b.Wait(context.Background(), CommandQuery)
+	st.Expect(t, err, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = b.Wait(ctx, CommandQuery)
+	st.Expect(t, err, context.DeadlineExceeded)
+	// The abandoned reservation is returned to the bucket.
+	st.Expect(t, b.tokens > -1, true)
+}
+
+// testLimiter records the class of each command it is asked to allow.
+type testLimiter struct {
+	classes []CommandClass
+}
+
+func (l *testLimiter) Wait(ctx context.Context, class CommandClass) error {
+	l.classes = append(l.classes, class)
+	return nil
+}
+
+func TestCommandLimiter(t *testing.T) {
+	var query, transform testLimiter
+	l := &CommandLimiter{Query: &query, Transform: &transform}
+	l.Wait(context.Background(), CommandQuery)
+	l.Wait(context.Background(), CommandTransform)
+	l.Wait(context.Background(), CommandQuery)
+	st.Expect(t, query.classes, []CommandClass{CommandQuery, CommandQuery})
+	st.Expect(t, transform.classes, []CommandClass{CommandTransform})
+
+	err := (&CommandLimiter{}).Wait(context.Background(), CommandTransform)
+	st.Expect(t, err, nil)
+}
+
+func TestConnLimiter(t *testing.T) {
+	var s testPoolServer
+	var l testLimiter
+	p := &Pool{Dial: s.dial, User: "user", Password: "pass", Limiter: &l}
+	err := p.Do(context.Background(), func(c *Conn) error {
+		_, err := c.CheckDomain("example.com")
+		if err != nil {
+			return err
+		}
+		_, err = c.DomainDelete("example.com")
+		return err
+	})
+	st.Expect(t, err, nil)
+	p.Close()
+	// login, check, delete, logout
+	st.Expect(t, l.classes, []CommandClass{CommandQuery, CommandQuery, CommandTransform, CommandQuery})
+}
@@ -0,0 +1,90 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+var errMissingExpiry = errors.New("epp: unable to determine current expiration date")
+
+// DomainRenew renews a domain for period, which may be zero to use the
+// server default. To guard against renewing twice, the server verifies that
+// curExpDate matches the current expiration date of the domain. Only the
+// date portion of curExpDate is used. If curExpDate is zero, it is fetched
+// with a domain <info> command before renewing.
+// https://tools.ietf.org/html/rfc5731#section-3.2.3
+func (c *Conn) DomainRenew(domain string, curExpDate time.Time, period Period) (*DomainRenewResponse, error) {
+	return c.DomainRenewContext(context.Background(), domain, curExpDate, period)
+}
+
+// DomainRenewContext is like DomainRenew, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainRenewContext(ctx context.Context, domain string, curExpDate time.Time, period Period) (*DomainRenewResponse, error) {
+	if curExpDate.IsZero() {
+		dir, err := c.DomainInfoContext(ctx, domain, nil)
+		if err != nil {
+			return nil, err
+		}
+		if dir.ExDate.IsZero() {
+			return nil, errMissingExpiry
+		}
+		curExpDate = dir.ExDate
+	}
+	x, err := encodeDomainRenew(domain, curExpDate, period)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainRenew, nil
+}
+
+func encodeDomainRenew(domain string, curExpDate time.Time, period Period) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<renew><domain:renew xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	err := encodeDomainName(buf, domain)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`<domain:curExpDate>`)
+	buf.WriteString(curExpDate.Format(time.DateOnly))
+	buf.WriteString(`</domain:curExpDate>`)
+	err = encodePeriod(buf, period)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`</domain:renew></renew>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// DomainRenewResponse represents an EPP response for a domain renew request.
+// https://tools.ietf.org/html/rfc5731#section-3.2.3
+type DomainRenewResponse struct {
+	Domain string    // <domain:name>
+	ExDate time.Time // <domain:exDate>
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjDomain + " renData"
+	scanResponse.MustHandleCharData(path+">name", func(c *xx.Context) error {
+		drr := &c.Value.(*Response).DomainRenew
+		drr.Domain = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">exDate", func(c *xx.Context) error {
+		drr := &c.Value.(*Response).DomainRenew
+		var err error
+		drr.ExDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+}
@@ -0,0 +1,85 @@
+package epp
+
+import (
+	"encoding/xml"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainRenew(t *testing.T) {
+	x, err := encodeDomainRenew("example.com", time.Date(2000, 4, 3, 22, 0, 0, 0, time.UTC), Years(5))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><renew><domain:renew xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:curExpDate>2000-04-03</domain:curExpDate><domain:period unit="y">5</domain:period></domain:renew></renew></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestScanDomainRenewResponse(t *testing.T) {
+	var res Response
+	drr := &res.DomainRenew
+
+	d := decoder(testXMLDomainRenewResponse)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, drr.Domain, "example.com")
+	st.Expect(t, drr.ExDate, time.Date(2005, 4, 3, 22, 0, 0, 0, time.UTC))
+}
+
+func TestDomainRenewFetchesExpiry(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		// Respond to info request with the current expiry date
+		x, err := readTestRequest(conn)
+		st.Assert(t, err, nil)
+		st.Expect(t, strings.Contains(x, "<info>"), true)
+		err = writeDataUnit(conn, []byte(testXMLDomainInfoResponse))
+		st.Assert(t, err, nil)
+		// Renew request should quote the fetched expiry date
+		x, err = readTestRequest(conn)
+		st.Assert(t, err, nil)
+		st.Expect(t, testElement(x, "domain:curExpDate"), "2000-04-03")
+		err = writeDataUnit(conn, []byte(testXMLDomainRenewResponse))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	drr, err := c.DomainRenew("example.com", time.Time{}, Years(5))
+	st.Expect(t, err, nil)
+	st.Expect(t, drr.ExDate, time.Date(2005, 4, 3, 22, 0, 0, 0, time.UTC))
+}
+
+var testXMLDomainRenewResponse = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1000">
+			<msg>Command completed successfully</msg>
+		</result>
+		<resData>
+			<domain:renData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>example.com</domain:name>
+				<domain:exDate>2005-04-03T22:00:00.0Z</domain:exDate>
+			</domain:renData>
+		</resData>
+		<trID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
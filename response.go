@@ -1,20 +1,93 @@
 package epp
 
-import "github.com/nbio/xx"
+import (
+	"bytes"
+	"encoding/xml"
+
+	"github.com/nbio/xx"
+)
 
 // Response represents an EPP response.
+// The embedded Result is the first of Results.
 type Response struct {
 	Result
+	Results []Result
 	Greeting
 	DomainCheckResponse
 	DomainInfoResponse
+
+	// Other command responses are in named fields, so their field
+	// names do not conflict with the embedded domain responses.
+	DomainCreate    DomainCreateResponse
+	DomainRenew     DomainRenewResponse
+	DomainTransfer  DomainTransferResponse
+	HostCheck       HostCheckResponse
+	HostInfo        HostInfoResponse
+	HostCreate      HostCreateResponse
+	ContactCheck    ContactCheckResponse
+	ContactInfo     ContactInfoResponse
+	ContactCreate   ContactCreateResponse
+	ContactTransfer ContactTransferResponse
+
+	// MessageQueue holds the <msgQ> element, if present. Servers may
+	// include it in any response, not just responses to poll commands.
+	MessageQueue MessageQueue
+
+	// DomainPendingAction, HostPendingAction and ContactPendingAction hold
+	// <domain:panData>, <host:panData> and <contact:panData> elements, if present.
+	DomainPendingAction  PendingAction
+	HostPendingAction    PendingAction
+	ContactPendingAction PendingAction
+
+	// isGreeting is true if the root element is a <greeting>.
+	isGreeting bool
+
+	// raw holds the raw response XML, if available.
+	raw []byte
 }
 
 var scanResponse = xx.NewScanner()
 
 func init() {
 	scanResponse.MustHandleStartElement("epp", func(c *xx.Context) error {
-		*c.Value.(*Response) = Response{}
+		res := c.Value.(*Response)
+		*res = Response{raw: res.raw}
 		return nil
 	})
 }
+
+// scanDataUnit scans an EPP data unit p into a Response.
+func scanDataUnit(p []byte) (*Response, error) {
+	res := &Response{raw: p}
+	err := IgnoreEOF(scanResponse.Scan(xml.NewDecoder(bytes.NewReader(p)), res))
+	return res, err
+}
+
+// innerXML returns the raw XML content of the element in res whose start tag
+// was the last token read by c. It returns an empty string if the raw
+// response XML is unavailable.
+func innerXML(c *xx.Context) string {
+	p := c.Value.(*Response).raw
+	offset := c.Decoder.InputOffset()
+	if offset < 2 || offset > int64(len(p)) || string(p[offset-2:offset]) == "/>" {
+		return ""
+	}
+	d := xml.NewDecoder(bytes.NewReader(p[offset:]))
+	depth := 0
+	for {
+		end := d.InputOffset()
+		t, err := d.RawToken()
+		if err != nil {
+			return ""
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return string(bytes.TrimSpace(p[offset : offset+end]))
+			}
+			depth--
+		}
+	}
+}
@@ -55,3 +55,15 @@ func TestScanCheckDomainResponseWithPremiumAttributeWithFee10(t *testing.T) {
 	st.Expect(t, dcr.Charges[0].Category, "premium")
 	st.Expect(t, dcr.Charges[0].CategoryName, "Registration Fee")
 }
+
+func TestResponsePromotedFields(t *testing.T) {
+	// Fields of the embedded check and info responses
+	// are promoted unambiguously.
+	var res Response
+	res.DomainCheckResponse.Checks = []DomainCheck{{Domain: "example.com"}}
+	res.DomainInfoResponse.Status = []string{"ok"}
+	st.Expect(t, res.Checks[0].Domain, "example.com")
+	st.Expect(t, res.Status, []string{"ok"})
+	st.Expect(t, res.CrDate.IsZero(), true)
+	st.Expect(t, res.ExDate.IsZero(), true)
+}
@@ -1,7 +1,9 @@
 package epp
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/nbio/xx"
 )
@@ -11,8 +13,76 @@ type Result struct {
 	Code    int    `xml:"code,attr"`
 	Message string `xml:"msg"`
 	Reason  string `xml:"extValue>reason,omitempty"`
+
+	// Language is the lang attribute of <msg>, if present.
+	Language string `xml:"-"`
+
+	// Values holds the raw XML of each <value> element,
+	// identifying a client-provided element that caused an error.
+	Values []string `xml:"-"`
+
+	// ExtValues holds each <extValue> element. Reason is set to the first reason.
+	ExtValues []ExtValue `xml:"-"`
+
+	// ClientTransactionID and ServerTransactionID hold the <clTRID> and <svTRID>
+	// values from the <trID> element of the response containing this result.
+	// https://tools.ietf.org/html/rfc5730#section-2.6
+	ClientTransactionID string `xml:"-"`
+	ServerTransactionID string `xml:"-"`
+
+	// SecurityEvents holds the login security events returned in response
+	// to a <login> command, if the server supports RFC 8807.
+	SecurityEvents []SecurityEvent `xml:"-"`
+}
+
+// ExtValue represents an EPP <extValue> element, describing a
+// client-provided element and the reason it caused an error.
+// https://tools.ietf.org/html/rfc5730#section-2.6
+type ExtValue struct {
+	Value    string // raw XML of <value>
+	Reason   string // <reason>
+	Language string // <reason lang="...">
 }
 
+// EPP result codes.
+// https://tools.ietf.org/html/rfc5730#section-3
+const (
+	ResultSuccess                              = 1000 // Command completed successfully
+	ResultSuccessPending                       = 1001 // Command completed successfully; action pending
+	ResultSuccessNoMessages                    = 1300 // Command completed successfully; no messages
+	ResultSuccessAckToDequeue                  = 1301 // Command completed successfully; ack to dequeue
+	ResultSuccessEndingSession                 = 1500 // Command completed successfully; ending session
+	ResultUnknownCommand                       = 2000 // Unknown command
+	ResultCommandSyntaxError                   = 2001 // Command syntax error
+	ResultCommandUseError                      = 2002 // Command use error
+	ResultRequiredParameterMissing             = 2003 // Required parameter missing
+	ResultParameterValueRangeError             = 2004 // Parameter value range error
+	ResultParameterValueSyntaxError            = 2005 // Parameter value syntax error
+	ResultUnimplementedProtocolVersion         = 2100 // Unimplemented protocol version
+	ResultUnimplementedCommand                 = 2101 // Unimplemented command
+	ResultUnimplementedOption                  = 2102 // Unimplemented option
+	ResultUnimplementedExtension               = 2103 // Unimplemented extension
+	ResultBillingFailure                       = 2104 // Billing failure
+	ResultObjectNotEligibleForRenewal          = 2105 // Object is not eligible for renewal
+	ResultObjectNotEligibleForTransfer         = 2106 // Object is not eligible for transfer
+	ResultAuthenticationError                  = 2200 // Authentication error
+	ResultAuthorizationError                   = 2201 // Authorization error
+	ResultInvalidAuthorizationInformation      = 2202 // Invalid authorization information
+	ResultObjectPendingTransfer                = 2300 // Object pending transfer
+	ResultObjectNotPendingTransfer             = 2301 // Object not pending transfer
+	ResultObjectExists                         = 2302 // Object exists
+	ResultObjectDoesNotExist                   = 2303 // Object does not exist
+	ResultObjectStatusProhibitsOperation       = 2304 // Object status prohibits operation
+	ResultObjectAssociationProhibitsOperation  = 2305 // Object association prohibits operation
+	ResultParameterValuePolicyError            = 2306 // Parameter value policy error
+	ResultUnimplementedObjectService           = 2307 // Unimplemented object service
+	ResultDataManagementPolicyViolation        = 2308 // Data management policy violation
+	ResultCommandFailed                        = 2400 // Command failed
+	ResultCommandFailedServerClosingConnection = 2500 // Command failed; server closing connection
+	ResultAuthenticationErrorServerClosing     = 2501 // Authentication error; server closing connection
+	ResultSessionLimitExceeded                 = 2502 // Session limit exceeded; server closing connection
+)
+
 // IsError determines whether an EPP status code is an error.
 // https://tools.ietf.org/html/rfc5730#section-3
 func (r *Result) IsError() bool {
@@ -26,24 +96,223 @@ func (r *Result) IsFatal() bool {
 	return r.Code >= 2500
 }
 
+// IsAuthError reports whether err is a *Result indicating an authentication
+// or authorization failure, including invalid authorization information.
+func IsAuthError(err error) bool {
+	return hasResultCode(err,
+		ResultAuthenticationError,
+		ResultAuthorizationError,
+		ResultInvalidAuthorizationInformation,
+		ResultAuthenticationErrorServerClosing)
+}
+
+// IsObjectNotFound reports whether err is a *Result with code 2303 (object does not exist).
+func IsObjectNotFound(err error) bool {
+	return hasResultCode(err, ResultObjectDoesNotExist)
+}
+
+// IsObjectExists reports whether err is a *Result with code 2302 (object exists).
+func IsObjectExists(err error) bool {
+	return hasResultCode(err, ResultObjectExists)
+}
+
+// IsSessionLimit reports whether err is a *Result with code 2502 (session limit exceeded).
+func IsSessionLimit(err error) bool {
+	return hasResultCode(err, ResultSessionLimitExceeded)
+}
+
+// IsRateLimited reports whether err is a *Result indicating the client has
+// exceeded a server-defined command rate. EPP defines no result code for this,
+// so registries typically return 2502 or 2400 with an explanatory message.
+// Messages about too many sessions are not matched; see IsSessionLimit.
+func IsRateLimited(err error) bool {
+	var r *Result
+	if !errors.As(err, &r) || !r.IsError() {
+		return false
+	}
+	s := strings.ToLower(r.Message + " " + r.Reason)
+	return strings.Contains(s, "rate limit") ||
+		strings.Contains(s, "rate exceeded") ||
+		strings.Contains(s, "too many commands") ||
+		strings.Contains(s, "too many requests")
+}
+
+// IsPasswordExpired reports whether err is a *Result indicating the login
+// password has expired. EPP defines no result code for this, so it matches
+// an RFC 8807 password event at error level, or an authentication error
+// whose message mentions an expired password.
+func IsPasswordExpired(err error) bool {
+	var r *Result
+	if !errors.As(err, &r) || !r.IsError() {
+		return false
+	}
+	for _, e := range r.SecurityEvents {
+		if e.Type == SecurityEventPassword && e.IsError() {
+			return true
+		}
+	}
+	if !IsAuthError(err) {
+		return false
+	}
+	s := strings.ToLower(r.Message + " " + r.Reason)
+	return strings.Contains(s, "password") && strings.Contains(s, "expire")
+}
+
+// hasResultCode reports whether err is a *Result with any of codes.
+func hasResultCode(err error, codes ...int) bool {
+	var r *Result
+	if !errors.As(err, &r) {
+		return false
+	}
+	for _, code := range codes {
+		if r.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
 // Error implements the error interface.
+// It summarizes the result message and all reasons given in r.
+// The server transaction ID, if any, is included for reference.
 func (r *Result) Error() string {
-	return fmt.Sprintf("EPP result code %d: %s", r.Code, r.Message)
+	var b strings.Builder
+	r.summarize(&b)
+	if r.ServerTransactionID != "" {
+		fmt.Fprintf(&b, " (svTRID %s)", r.ServerTransactionID)
+	}
+	return b.String()
+}
+
+// summarize writes the code, message and reasons of r to b.
+func (r *Result) summarize(b *strings.Builder) {
+	fmt.Fprintf(b, "EPP result code %d: %s", r.Code, r.Message)
+	var reasons []string
+	for _, v := range r.ExtValues {
+		if v.Reason != "" {
+			reasons = append(reasons, v.Reason)
+		}
+	}
+	if len(reasons) == 0 && r.Reason != "" {
+		reasons = append(reasons, r.Reason)
+	}
+	if len(reasons) > 0 {
+		b.WriteString(": ")
+		b.WriteString(strings.Join(reasons, "; "))
+	}
+}
+
+// ResultError is the error returned for an error response with more than
+// one <result> element. A response with a single <result> returns it as a
+// *Result. Using errors.As with a *Result target finds the first result.
+type ResultError struct {
+	Results []Result
+}
+
+// Error implements the error interface.
+// It summarizes the code, message and reasons of each result.
+func (e *ResultError) Error() string {
+	var b strings.Builder
+	for i := range e.Results {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		e.Results[i].summarize(&b)
+	}
+	if len(e.Results) > 0 && e.Results[0].ServerTransactionID != "" {
+		fmt.Fprintf(&b, " (svTRID %s)", e.Results[0].ServerTransactionID)
+	}
+	return b.String()
+}
+
+// Unwrap returns each result in e as a *Result.
+func (e *ResultError) Unwrap() []error {
+	errs := make([]error, len(e.Results))
+	for i := range e.Results {
+		errs[i] = &e.Results[i]
+	}
+	return errs
+}
+
+// scanResult calls f with the <result> element currently being scanned,
+// keeping the first result in sync with the embedded Response.Result.
+func scanResult(c *xx.Context, f func(r *Result)) {
+	res := c.Value.(*Response)
+	if len(res.Results) == 0 {
+		return
+	}
+	f(&res.Results[len(res.Results)-1])
+	if len(res.Results) == 1 {
+		res.Result = res.Results[0]
+	}
 }
 
 func init() {
 	path := "epp > response > result"
 	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
 		res := c.Value.(*Response)
-		res.Result.Code = c.AttrInt("", "code")
+		res.Results = append(res.Results, Result{})
+		scanResult(c, func(r *Result) {
+			r.Code = c.AttrInt("", "code")
+		})
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> msg", func(c *xx.Context) error {
+		scanResult(c, func(r *Result) {
+			r.Language = c.Attr("", "lang")
+		})
 		return nil
 	})
 	scanResponse.MustHandleCharData(path+"> msg", func(c *xx.Context) error {
-		c.Value.(*Response).Result.Message = string(c.CharData)
+		scanResult(c, func(r *Result) {
+			r.Message = string(c.CharData)
+		})
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> value", func(c *xx.Context) error {
+		scanResult(c, func(r *Result) {
+			r.Values = append(r.Values, innerXML(c))
+		})
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> extValue", func(c *xx.Context) error {
+		scanResult(c, func(r *Result) {
+			r.ExtValues = append(r.ExtValues, ExtValue{})
+		})
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> extValue > value", func(c *xx.Context) error {
+		scanResult(c, func(r *Result) {
+			r.ExtValues[len(r.ExtValues)-1].Value = innerXML(c)
+		})
 		return nil
 	})
 	scanResponse.MustHandleCharData(path+"> extValue > reason", func(c *xx.Context) error {
-		c.Value.(*Response).Result.Reason = string(c.CharData)
+		scanResult(c, func(r *Result) {
+			v := &r.ExtValues[len(r.ExtValues)-1]
+			v.Reason = string(c.CharData)
+			v.Language = c.Attr("", "lang")
+			if r.Reason == "" {
+				r.Reason = v.Reason
+			}
+		})
+		return nil
+	})
+	path = "epp > response > trID"
+	scanResponse.MustHandleCharData(path+"> clTRID", func(c *xx.Context) error {
+		res := c.Value.(*Response)
+		res.Result.ClientTransactionID = string(c.CharData)
+		for i := range res.Results {
+			res.Results[i].ClientTransactionID = res.Result.ClientTransactionID
+		}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+"> svTRID", func(c *xx.Context) error {
+		res := c.Value.(*Response)
+		res.Result.ServerTransactionID = string(c.CharData)
+		for i := range res.Results {
+			res.Results[i].ServerTransactionID = res.Result.ServerTransactionID
+		}
 		return nil
 	})
 }
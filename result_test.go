@@ -1,6 +1,11 @@
 package epp
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/nbio/st"
@@ -47,6 +52,94 @@ func TestScanResult(t *testing.T) {
 	st.Expect(t, r.IsFatal(), true)
 }
 
+func TestScanResultTransactionID(t *testing.T) {
+	var res Response
+	d := decoder(`<epp><response><result code="2303"><msg>Object does not exist</msg></result><trID><clTRID>ABC-12345</clTRID><svTRID>54322-XYZ</svTRID></trID></response></epp>`)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.ClientTransactionID, "ABC-12345")
+	st.Expect(t, res.ServerTransactionID, "54322-XYZ")
+	st.Expect(t, res.Result.Error(), "EPP result code 2303: Object does not exist (svTRID 54322-XYZ)")
+}
+
+func TestScanMultipleResults(t *testing.T) {
+	res, err := scanDataUnit([]byte(testXMLMultipleResultsResponse))
+	st.Expect(t, err, nil)
+	st.Expect(t, len(res.Results), 2)
+	st.Expect(t, res.Result.Code, 2004)
+	st.Expect(t, res.Result.Language, "en")
+	st.Expect(t, res.Result.Values, []string{`<obj:elem1>2525</obj:elem1>`})
+	st.Expect(t, res.Result.ServerTransactionID, "54321-XYZ")
+
+	r := &res.Results[1]
+	st.Expect(t, r.Code, 2005)
+	st.Expect(t, r.Message, "Parameter value syntax error")
+	st.Expect(t, r.Language, "")
+	st.Expect(t, r.Values, []string{`<obj:elem2>ex(ample</obj:elem2>`})
+	st.Expect(t, r.ExtValues, []ExtValue{
+		{Value: `<obj:elem3>abc.ex(ample</obj:elem3>`, Reason: "Caractère invalide", Language: "fr"},
+		{Reason: "Another reason"},
+	})
+	st.Expect(t, r.Reason, "Caractère invalide")
+	st.Expect(t, r.ClientTransactionID, "ABC-12345")
+	st.Expect(t, r.Error(), "EPP result code 2005: Parameter value syntax error: Caractère invalide; Another reason (svTRID 54321-XYZ)")
+}
+
+func TestMultipleResultsError(t *testing.T) {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		err := writeDataUnit(server, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		x, err := readTestRequest(server)
+		st.Assert(t, err, nil)
+		res := strings.Replace(testXMLMultipleResultsResponse, "ABC-12345", testElement(x, "clTRID"), 1)
+		err = writeDataUnit(server, []byte(res))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, server)
+	}()
+	c, err := NewConn(client)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	_, err = c.CheckDomain("example.com")
+	var re *ResultError
+	st.Assert(t, errors.As(err, &re), true)
+	st.Expect(t, len(re.Results), 2)
+	st.Expect(t, err.Error(), "EPP result code 2004: Parameter value range error; "+
+		"EPP result code 2005: Parameter value syntax error: Caractère invalide; Another reason (svTRID 54321-XYZ)")
+	var r *Result
+	st.Assert(t, errors.As(err, &r), true)
+	st.Expect(t, r.Code, ResultParameterValueRangeError)
+	st.Expect(t, errors.Is(err, &re.Results[1]), true)
+}
+
+func TestResultPredicates(t *testing.T) {
+	wrap := func(r *Result) error {
+		return fmt.Errorf("wrapped: %w", r)
+	}
+	st.Expect(t, IsObjectNotFound(wrap(&Result{Code: ResultObjectDoesNotExist})), true)
+	st.Expect(t, IsObjectNotFound(&Result{Code: ResultObjectExists}), false)
+	st.Expect(t, IsObjectExists(&Result{Code: ResultObjectExists}), true)
+	st.Expect(t, IsAuthError(&Result{Code: ResultAuthenticationError}), true)
+	st.Expect(t, IsAuthError(wrap(&Result{Code: ResultAuthenticationErrorServerClosing})), true)
+	st.Expect(t, IsAuthError(&Result{Code: ResultCommandFailed}), false)
+	st.Expect(t, IsSessionLimit(&Result{Code: ResultSessionLimitExceeded}), true)
+	st.Expect(t, IsRateLimited(&Result{Code: ResultSessionLimitExceeded, Message: "Command rate limit exceeded"}), true)
+	st.Expect(t, IsRateLimited(&Result{Code: ResultSessionLimitExceeded, Message: "Session limit exceeded"}), false)
+	st.Expect(t, IsRateLimited(&Result{Code: ResultSuccess, Message: "Rate limit warning"}), false)
+	st.Expect(t, IsRateLimited(&Result{Code: ResultSessionLimitExceeded, Message: "Too many sessions"}), false)
+	st.Expect(t, IsRateLimited(&Result{Code: ResultCommandFailed, Message: "Too many commands per second"}), true)
+	st.Expect(t, IsPasswordExpired(&Result{Code: ResultAuthenticationError, Message: "Password has expired"}), true)
+	st.Expect(t, IsPasswordExpired(&Result{Code: ResultAuthenticationError, Message: "Authentication error"}), false)
+	st.Expect(t, IsPasswordExpired(wrap(&Result{Code: ResultAuthenticationError, SecurityEvents: []SecurityEvent{
+		{Type: SecurityEventPassword, Level: SecurityLevelError},
+	}})), true)
+	st.Expect(t, IsPasswordExpired(&Result{Code: ResultSuccess, Message: "Password expires soon"}), false)
+	st.Expect(t, IsObjectNotFound(nil), false)
+	st.Expect(t, IsObjectNotFound(fmt.Errorf("not a result")), false)
+}
+
 func BenchmarkScanResult(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
@@ -56,3 +149,35 @@ func BenchmarkScanResult(b *testing.B) {
 		scanResponse.Scan(d, &res)
 	}
 }
+
+var testXMLMultipleResultsResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="2004">
+			<msg lang="en">Parameter value range error</msg>
+			<value xmlns:obj="urn:ietf:params:xml:ns:obj">
+				<obj:elem1>2525</obj:elem1>
+			</value>
+		</result>
+		<result code="2005">
+			<msg>Parameter value syntax error</msg>
+			<value xmlns:obj="urn:ietf:params:xml:ns:obj">
+				<obj:elem2>ex(ample</obj:elem2>
+			</value>
+			<extValue>
+				<value xmlns:obj="urn:ietf:params:xml:ns:obj">
+					<obj:elem3>abc.ex(ample</obj:elem3>
+				</value>
+				<reason lang="fr">Caractère invalide</reason>
+			</extValue>
+			<extValue>
+				<value/>
+				<reason>Another reason</reason>
+			</extValue>
+		</result>
+		<trID>
+			<clTRID>ABC-12345</clTRID>
+			<svTRID>54321-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
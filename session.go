@@ -2,36 +2,142 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
+	"slices"
+)
+
+var (
+	errUnsupportedVersion   = errors.New("epp: protocol version not supported by server")
+	errUnsupportedLanguage  = errors.New("epp: language not supported by server")
+	errUnsupportedObject    = errors.New("epp: object not supported by server")
+	errUnsupportedExtension = errors.New("epp: extension not supported by server")
 )
 
 // Login initializes an authenticated EPP session.
+// If the server greeting advertises the login security extension (RFC 8807),
+// the passwords are sent in the extension, along with DefaultUserAgent, and
+// any security events returned by the server are in Result.SecurityEvents.
 // https://tools.ietf.org/html/rfc5730#section-2.9.1.1
 func (c *Conn) Login(user, password, newPassword string) (Result, error) {
-	err := c.writeLogin(user, password, newPassword)
+	return c.LoginContext(context.Background(), user, password, newPassword)
+}
+
+// LoginContext is like Login, but aborts if ctx is done before the server responds.
+func (c *Conn) LoginContext(ctx context.Context, user, password, newPassword string) (Result, error) {
+	return c.LoginWithOptionsContext(ctx, user, password, newPassword, nil)
+}
+
+// LoginOptions are options for an EPP <login> command.
+// Empty fields default to values from the server greeting.
+type LoginOptions struct {
+	// Version is the protocol version. Defaults to the first
+	// version in the greeting, or "1.0".
+	Version string
+
+	// Language is the language for server messages. Defaults to
+	// the first language in the greeting, or "en".
+	Language string
+
+	// Objects are the object URIs (<objURI>) to use in the session.
+	// Defaults to all objects in the greeting.
+	Objects []string
+
+	// Extensions are the extension URIs (<extURI>) to use in the session.
+	// Defaults to all extensions in the greeting. To use no extensions,
+	// set to a non-nil empty slice.
+	Extensions []string
+
+	// UserAgent identifies the client to servers that support the
+	// login security extension (RFC 8807). Defaults to DefaultUserAgent.
+	// To send no user agent, set to a pointer to an empty UserAgent.
+	UserAgent *UserAgent
+}
+
+// LoginWithOptions is like Login, using the version, language, objects and
+// extensions in opts. It returns an error without sending a command if opts
+// requests anything not advertised in the server greeting. A nil opts is
+// equivalent to Login.
+func (c *Conn) LoginWithOptions(user, password, newPassword string, opts *LoginOptions) (Result, error) {
+	return c.LoginWithOptionsContext(context.Background(), user, password, newPassword, opts)
+}
+
+// LoginWithOptionsContext is like LoginWithOptions, but aborts if ctx is done before the server responds.
+func (c *Conn) LoginWithOptionsContext(ctx context.Context, user, password, newPassword string, opts *LoginOptions) (Result, error) {
+	tx, err := c.writeLogin(ctx, user, password, newPassword, opts)
 	if err != nil {
 		return Result{}, err
 	}
-	res, err := c.readResponse()
+	res, err := c.readResponse(ctx, tx)
 	if err != nil {
 		return Result{}, err
 	}
 	return res.Result, nil
 }
 
-func (c *Conn) writeLogin(user, password, newPassword string) error {
-	ver, lang := "1.0", "en"
-	if len(c.Greeting.Versions) > 0 {
-		ver = c.Greeting.Versions[0]
+func (c *Conn) writeLogin(ctx context.Context, user, password, newPassword string, opts *LoginOptions) (*transaction, error) {
+	g := c.LastGreeting()
+	o, err := g.loginOptions(opts)
+	if err != nil {
+		return nil, err
 	}
-	if len(c.Greeting.Languages) > 0 {
-		lang = c.Greeting.Languages[0]
+	var x []byte
+	if slices.Contains(o.Extensions, ExtLoginSec) {
+		ua := o.UserAgent
+		if ua == nil {
+			ua = &DefaultUserAgent
+		}
+		x, err = encodeLoginSec(user, password, newPassword, ua, o.Version, o.Language, o.Objects, o.Extensions)
+	} else {
+		x, err = encodeLogin(user, password, newPassword, o.Version, o.Language, o.Objects, o.Extensions)
 	}
-	x, err := encodeLogin(user, password, newPassword, ver, lang, c.Greeting.Objects, c.Greeting.Extensions)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return c.writeRequest(x)
+	return c.writeRequest(ctx, x)
+}
+
+// loginOptions returns opts with defaults filled in from g,
+// or an error if opts requests anything g does not advertise.
+func (g *Greeting) loginOptions(opts *LoginOptions) (LoginOptions, error) {
+	var o LoginOptions
+	if opts != nil {
+		o = *opts
+	}
+	switch {
+	case o.Version == "" && len(g.Versions) > 0:
+		o.Version = g.Versions[0]
+	case o.Version == "":
+		o.Version = "1.0"
+	case len(g.Versions) > 0 && !slices.Contains(g.Versions, o.Version):
+		return o, errUnsupportedVersion
+	}
+	switch {
+	case o.Language == "" && len(g.Languages) > 0:
+		o.Language = g.Languages[0]
+	case o.Language == "":
+		o.Language = "en"
+	case len(g.Languages) > 0 && !slices.Contains(g.Languages, o.Language):
+		return o, errUnsupportedLanguage
+	}
+	if o.Objects == nil {
+		o.Objects = g.Objects
+	}
+	for _, uri := range o.Objects {
+		if !g.SupportsObject(uri) {
+			return o, errUnsupportedObject
+		}
+	}
+	if o.Extensions == nil {
+		o.Extensions = g.Extensions
+	}
+	for _, uri := range o.Extensions {
+		if !g.SupportsExtension(uri) {
+			return o, errUnsupportedExtension
+		}
+	}
+	return o, nil
 }
 
 func encodeLogin(user, password, newPassword, version, language string, objects, extensions []string) ([]byte, error) {
@@ -73,11 +179,16 @@ func encodeLogin(user, password, newPassword, version, language string, objects,
 // Logout sends a <logout> command to terminate an EPP session.
 // https://tools.ietf.org/html/rfc5730#section-2.9.1.2
 func (c *Conn) Logout() error {
-	err := c.writeRequest(xmlLogout)
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext is like Logout, but aborts if ctx is done before the server responds.
+func (c *Conn) LogoutContext(ctx context.Context) error {
+	tx, err := c.writeRequest(ctx, xmlLogout)
 	if err != nil {
 		return err
 	}
-	_, err = c.readResponse()
+	_, err = c.readResponse(ctx, tx)
 	return err
 }
 
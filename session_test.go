@@ -2,6 +2,7 @@ package epp
 
 import (
 	"encoding/xml"
+	"io"
 	"net"
 	"testing"
 
@@ -76,10 +77,88 @@ func TestLoginReturnsResult(t *testing.T) {
 	st.Expect(t, err, nil)
 	st.Expect(t, result.Code, 1000)
 	st.Expect(t, result.Message, "Command completed successfully")
+	st.Expect(t, result.ServerTransactionID, "12345")
 
 	c.Close()
 }
 
+func TestGreetingLoginOptions(t *testing.T) {
+	g := Greeting{
+		Versions:   []string{"1.0"},
+		Languages:  []string{"en", "fr"},
+		Objects:    []string{ObjDomain, ObjHost, ObjContact},
+		Extensions: []string{ExtSecDNS, ExtRGP},
+	}
+
+	o, err := g.loginOptions(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, o, LoginOptions{
+		Version:    "1.0",
+		Language:   "en",
+		Objects:    g.Objects,
+		Extensions: g.Extensions,
+	})
+
+	o, err = g.loginOptions(&LoginOptions{Language: "fr", Objects: []string{ObjDomain}, Extensions: []string{}})
+	st.Expect(t, err, nil)
+	st.Expect(t, o, LoginOptions{
+		Version:    "1.0",
+		Language:   "fr",
+		Objects:    []string{ObjDomain},
+		Extensions: []string{},
+	})
+
+	_, err = g.loginOptions(&LoginOptions{Version: "2.0"})
+	st.Expect(t, err, errUnsupportedVersion)
+	_, err = g.loginOptions(&LoginOptions{Language: "de"})
+	st.Expect(t, err, errUnsupportedLanguage)
+	_, err = g.loginOptions(&LoginOptions{Objects: []string{ObjDomain, "urn:example:unknown"}})
+	st.Expect(t, err, errUnsupportedObject)
+	_, err = g.loginOptions(&LoginOptions{Extensions: []string{ExtLaunch}})
+	st.Expect(t, err, errUnsupportedExtension)
+
+	var empty Greeting
+	o, err = empty.loginOptions(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, o.Version, "1.0")
+	st.Expect(t, o.Language, "en")
+}
+
+func TestLoginWithOptions(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		err = writeDataUnit(conn, []byte(testXMLGreeting))
+		st.Assert(t, err, nil)
+		x, err := readTestRequest(conn)
+		st.Assert(t, err, nil)
+		st.Expect(t, testElement(x, "lang"), "fr")
+		st.Expect(t, testElement(x, "svcs"), "<objURI>urn:ietf:params:xml:ns:obj1</objURI>")
+		err = writeDataUnit(conn, []byte(testXMLLoginResponse))
+		st.Assert(t, err, nil)
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	defer c.Conn.Close()
+
+	_, err = c.LoginWithOptions("jane", "battery", "", &LoginOptions{Objects: []string{"urn:ietf:params:xml:ns:obj4"}})
+	st.Expect(t, err, errUnsupportedObject)
+
+	result, err := c.LoginWithOptions("jane", "battery", "", &LoginOptions{
+		Language:   "fr",
+		Objects:    []string{"urn:ietf:params:xml:ns:obj1"},
+		Extensions: []string{},
+	})
+	st.Expect(t, err, nil)
+	st.Expect(t, result.Code, ResultSuccess)
+}
+
 var testXMLLoginResponse = `<?xml version="1.0" encoding="UTF-8"?>
 <epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
 	<response>
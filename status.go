@@ -106,3 +106,44 @@ func ParseStatus(in ...string) Status {
 	}
 	return s
 }
+
+// statusToString maps single Status bits to EPP status strings.
+var statusToString = map[Status]string{
+	StatusOK:                       "ok",
+	StatusLinked:                   "linked",
+	StatusAddPeriod:                "addPeriod",
+	StatusAutoRenewPeriod:          "autoRenewPeriod",
+	StatusInactive:                 "inactive",
+	StatusPendingCreate:            "pendingCreate",
+	StatusPendingDelete:            "pendingDelete",
+	StatusPendingRenew:             "pendingRenew",
+	StatusPendingRestore:           "pendingRestore",
+	StatusPendingTransfer:          "pendingTransfer",
+	StatusPendingUpdate:            "pendingUpdate",
+	StatusRedemptionPeriod:         "redemptionPeriod",
+	StatusRenewPeriod:              "renewPeriod",
+	StatusServerDeleteProhibited:   "serverDeleteProhibited",
+	StatusServerHold:               "serverHold",
+	StatusServerRenewProhibited:    "serverRenewProhibited",
+	StatusServerTransferProhibited: "serverTransferProhibited",
+	StatusServerUpdateProhibited:   "serverUpdateProhibited",
+	StatusTransferPeriod:           "transferPeriod",
+	StatusClientDeleteProhibited:   "clientDeleteProhibited",
+	StatusClientHold:               "clientHold",
+	StatusClientRenewProhibited:    "clientRenewProhibited",
+	StatusClientTransferProhibited: "clientTransferProhibited",
+	StatusClientUpdateProhibited:   "clientUpdateProhibited",
+}
+
+// Strings returns the EPP status strings for each bit set in s, in priority order.
+func (s Status) Strings() []string {
+	var out []string
+	for b := Status(1); b != 0 && b <= s; b <<= 1 {
+		if s&b != 0 {
+			if v, ok := statusToString[b]; ok {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+// Transfer operations, used as the op attribute of a <transfer> command.
+// https://tools.ietf.org/html/rfc5730#section-2.9.3.4
+const (
+	TransferRequest = "request"
+	TransferQuery   = "query"
+	TransferApprove = "approve"
+	TransferReject  = "reject"
+	TransferCancel  = "cancel"
+)
+
+var errInvalidTransferOp = errors.New("epp: invalid transfer operation")
+
+// validTransferOp reports whether op is a valid transfer operation.
+func validTransferOp(op string) bool {
+	switch op {
+	case TransferRequest, TransferQuery, TransferApprove, TransferReject, TransferCancel:
+		return true
+	}
+	return false
+}
+
+// DomainTransfer performs transfer operation op on a domain.
+// The authInfo password is required to request a transfer, and optional
+// otherwise. The period, which may be zero, is only sent with TransferRequest.
+// https://tools.ietf.org/html/rfc5731#section-3.2.4
+func (c *Conn) DomainTransfer(op, domain, authInfo string, period Period) (*DomainTransferResponse, error) {
+	return c.DomainTransferContext(context.Background(), op, domain, authInfo, period)
+}
+
+// DomainTransferContext is like DomainTransfer, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainTransferContext(ctx context.Context, op, domain, authInfo string, period Period) (*DomainTransferResponse, error) {
+	x, err := encodeDomainTransfer(op, domain, authInfo, period)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainTransfer, nil
+}
+
+func encodeDomainTransfer(op, domain, authInfo string, period Period) ([]byte, error) {
+	if !validTransferOp(op) {
+		return nil, errInvalidTransferOp
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<transfer op="`)
+	buf.WriteString(op)
+	buf.WriteString(`"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	err := encodeDomainName(buf, domain)
+	if err != nil {
+		return nil, err
+	}
+	if op == TransferRequest {
+		err = encodePeriod(buf, period)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if authInfo != "" {
+		encodeDomainAuthInfo(buf, authInfo)
+	}
+	buf.WriteString(`</domain:transfer></transfer>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// DomainTransferResponse represents an EPP response for a domain transfer request.
+// https://tools.ietf.org/html/rfc5731#section-3.2.4
+type DomainTransferResponse struct {
+	Domain string    // <domain:name>
+	Status string    // <domain:trStatus>, e.g. "pending" or "clientApproved"
+	ReID   string    // <domain:reID>, the requesting client
+	ReDate time.Time // <domain:reDate>
+	AcID   string    // <domain:acID>, the client that should act
+	AcDate time.Time // <domain:acDate>
+	ExDate time.Time // <domain:exDate>, optional
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjDomain + " trnData"
+	scanResponse.MustHandleCharData(path+">name", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		dtr.Domain = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">trStatus", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		dtr.Status = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reID", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		dtr.ReID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reDate", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		var err error
+		dtr.ReDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">acID", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		dtr.AcID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">acDate", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		var err error
+		dtr.AcDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">exDate", func(c *xx.Context) error {
+		dtr := &c.Value.(*Response).DomainTransfer
+		var err error
+		dtr.ExDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+}
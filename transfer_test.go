@@ -0,0 +1,69 @@
+package epp
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainTransfer(t *testing.T) {
+	x, err := encodeDomainTransfer(TransferRequest, "example.com", "2fooBAR", Years(1))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="request"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="y">1</domain:period><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:transfer></transfer></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+
+	// Period is only sent with transfer requests.
+	x, err = encodeDomainTransfer(TransferQuery, "example.com", "", Years(1))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="query"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:transfer></transfer></command></epp>`)
+
+	_, err = encodeDomainTransfer("steal", "example.com", "", Period{})
+	st.Expect(t, err, errInvalidTransferOp)
+}
+
+func TestScanDomainTransferResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+	<response>
+		<result code="1001">
+			<msg>Command completed successfully; action pending</msg>
+		</result>
+		<resData>
+			<domain:trnData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+				<domain:name>example.com</domain:name>
+				<domain:trStatus>pending</domain:trStatus>
+				<domain:reID>ClientX</domain:reID>
+				<domain:reDate>2000-06-08T22:00:00.0Z</domain:reDate>
+				<domain:acID>ClientY</domain:acID>
+				<domain:acDate>2000-06-13T22:00:00.0Z</domain:acDate>
+				<domain:exDate>2002-09-08T22:00:00.0Z</domain:exDate>
+			</domain:trnData>
+		</resData>
+		<trID>
+			<clTRID>ABC-12345</clTRID>
+			<svTRID>54322-XYZ</svTRID>
+		</trID>
+	</response>
+</epp>`
+
+	var res Response
+	dtr := &res.DomainTransfer
+
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Result.Code, ResultSuccessPending)
+	st.Expect(t, dtr.Domain, "example.com")
+	st.Expect(t, dtr.Status, "pending")
+	st.Expect(t, dtr.ReID, "ClientX")
+	st.Expect(t, dtr.ReDate, time.Date(2000, 6, 8, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dtr.AcID, "ClientY")
+	st.Expect(t, dtr.AcDate, time.Date(2000, 6, 13, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dtr.ExDate, time.Date(2002, 9, 8, 22, 0, 0, 0, time.UTC))
+}
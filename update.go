@@ -0,0 +1,125 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+)
+
+// DomainUpdateRequest represents an EPP request to update a domain.
+// At least one of Add, Remove or Change must be non-empty.
+// https://tools.ietf.org/html/rfc5731#section-3.2.5
+type DomainUpdateRequest struct {
+	Domain string              // <domain:name>
+	Add    DomainUpdateSection // <domain:add>
+	Remove DomainUpdateSection // <domain:rem>
+	Change DomainChange        // <domain:chg>
+}
+
+// DomainUpdateSection represents the attributes added to or removed from a domain
+// in a <domain:add> or <domain:rem> element.
+type DomainUpdateSection struct {
+	Nameservers []Nameserver    // <domain:ns>
+	Contacts    []DomainContact // <domain:contact>
+	Status      Status          // <domain:status>; see ParseStatus
+}
+
+// IsZero reports whether s is empty.
+func (s *DomainUpdateSection) IsZero() bool {
+	return len(s.Nameservers) == 0 && len(s.Contacts) == 0 && s.Status == StatusUnknown
+}
+
+// DomainChange represents the attributes changed in a <domain:chg> element.
+// Empty fields are left unchanged.
+type DomainChange struct {
+	Registrant string // <domain:registrant>
+	AuthInfo   string // <domain:authInfo><domain:pw>
+}
+
+// IsZero reports whether chg is empty.
+func (chg *DomainChange) IsZero() bool {
+	return chg.Registrant == "" && chg.AuthInfo == ""
+}
+
+var errEmptyDomainUpdate = errors.New("epp: domain update requires at least one add, rem or chg element")
+
+// DomainUpdate updates a domain.
+// https://tools.ietf.org/html/rfc5731#section-3.2.5
+func (c *Conn) DomainUpdate(req *DomainUpdateRequest) (Result, error) {
+	return c.DomainUpdateContext(context.Background(), req)
+}
+
+// DomainUpdateContext is like DomainUpdate, but aborts if ctx is done before the server responds.
+func (c *Conn) DomainUpdateContext(ctx context.Context, req *DomainUpdateRequest) (Result, error) {
+	x, err := encodeDomainUpdate(req)
+	if err != nil {
+		return Result{}, err
+	}
+	tx, err := c.writeRequest(ctx, x)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := c.readResponse(ctx, tx)
+	if err != nil {
+		return Result{}, err
+	}
+	return res.Result, nil
+}
+
+func encodeDomainUpdate(req *DomainUpdateRequest) ([]byte, error) {
+	if req.Add.IsZero() && req.Remove.IsZero() && req.Change.IsZero() {
+		return nil, errEmptyDomainUpdate
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	err := encodeDomainName(buf, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Add.IsZero() {
+		buf.WriteString(`<domain:add>`)
+		err = encodeDomainUpdateSection(buf, &req.Add)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`</domain:add>`)
+	}
+	if !req.Remove.IsZero() {
+		buf.WriteString(`<domain:rem>`)
+		err = encodeDomainUpdateSection(buf, &req.Remove)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`</domain:rem>`)
+	}
+	if !req.Change.IsZero() {
+		buf.WriteString(`<domain:chg>`)
+		if req.Change.Registrant != "" {
+			buf.WriteString(`<domain:registrant>`)
+			xml.EscapeText(buf, []byte(req.Change.Registrant))
+			buf.WriteString(`</domain:registrant>`)
+		}
+		if req.Change.AuthInfo != "" {
+			encodeDomainAuthInfo(buf, req.Change.AuthInfo)
+		}
+		buf.WriteString(`</domain:chg>`)
+	}
+	buf.WriteString(`</domain:update></update>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+func encodeDomainUpdateSection(buf *bytes.Buffer, s *DomainUpdateSection) error {
+	err := encodeNameservers(buf, s.Nameservers)
+	if err != nil {
+		return err
+	}
+	encodeDomainContacts(buf, s.Contacts)
+	for _, status := range s.Status.Strings() {
+		buf.WriteString(`<domain:status s="`)
+		buf.WriteString(status)
+		buf.WriteString(`"/>`)
+	}
+	return nil
+}
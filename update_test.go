@@ -0,0 +1,64 @@
+package epp
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainUpdate(t *testing.T) {
+	req := &DomainUpdateRequest{
+		Domain: "example.com",
+		Add: DomainUpdateSection{
+			Nameservers: []Nameserver{{Host: "ns2.example.com"}},
+			Contacts:    []DomainContact{{Type: ContactTech, ID: "mak21"}},
+			Status:      ParseStatus("clientHold", "clientTransferProhibited"),
+		},
+		Remove: DomainUpdateSection{
+			Nameservers: []Nameserver{{Host: "ns1.example.com"}},
+			Contacts:    []DomainContact{{Type: ContactTech, ID: "sh8013"}},
+		},
+		Change: DomainChange{
+			Registrant: "sh8013",
+			AuthInfo:   "2BARfoo",
+		},
+	}
+	x, err := encodeDomainUpdate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:add><domain:ns><domain:hostObj>ns2.example.com</domain:hostObj></domain:ns><domain:contact type="tech">mak21</domain:contact><domain:status s="clientHold"/><domain:status s="clientTransferProhibited"/></domain:add><domain:rem><domain:ns><domain:hostObj>ns1.example.com</domain:hostObj></domain:ns><domain:contact type="tech">sh8013</domain:contact></domain:rem><domain:chg><domain:registrant>sh8013</domain:registrant><domain:authInfo><domain:pw>2BARfoo</domain:pw></domain:authInfo></domain:chg></domain:update></update></command></epp>`)
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeDomainUpdateStatusOnly(t *testing.T) {
+	req := &DomainUpdateRequest{
+		Domain: "example.com",
+		Remove: DomainUpdateSection{Status: StatusClientHold},
+	}
+	x, err := encodeDomainUpdate(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:rem><domain:status s="clientHold"/></domain:rem></domain:update></update></command></epp>`)
+}
+
+func TestEncodeDomainUpdateEmpty(t *testing.T) {
+	_, err := encodeDomainUpdate(&DomainUpdateRequest{Domain: "example.com"})
+	st.Expect(t, err, errEmptyDomainUpdate)
+}
+
+func TestStatusStrings(t *testing.T) {
+	st.Expect(t, StatusUnknown.Strings(), []string(nil))
+	st.Expect(t, StatusOK.Strings(), []string{"ok"})
+	st.Expect(t, StatusClient.Strings(), []string{
+		"clientDeleteProhibited",
+		"clientHold",
+		"clientRenewProhibited",
+		"clientTransferProhibited",
+		"clientUpdateProhibited",
+	})
+	s := ParseStatus("pending delete", "redemptionPeriod")
+	st.Expect(t, ParseStatus(s.Strings()...), s)
+}